@@ -0,0 +1,72 @@
+package trie
+
+import "strings"
+
+// AutocompletePhrase return the best documents and phrase suggestions for a phrase that is still being typed
+// All the words but the last one must be complete words, the last one is used as a prefix
+// The suggestions are the typed phrase with the last word completed by the words of the documents that match the complete words
+func (t *Node) AutocompletePhrase(input string, k int) PhraseCompletion {
+	words := strings.Fields(input)
+	if k <= 0 || len(words) == 0 {
+		return PhraseCompletion{}
+	}
+	var postingList []*postings
+	for _, word := range words[:len(words)-1] {
+		cleanedString := cleanString(word)
+		if len(cleanedString) < minWordSize {
+			continue
+		}
+		node := t.getNode(cleanedString)
+		if node == nil || !node.isWord {
+			return PhraseCompletion{}
+		}
+		postingList = append(postingList, unionPostingList([]*postings{&node.correctData}))
+	}
+	lastWord := cleanString(words[len(words)-1])
+	node := t.getNode(lastWord)
+	if lastWord == "" || node == nil {
+		return PhraseCompletion{}
+	}
+	exact := intersectPostingList(postingList)
+	prefix := unionPostings(&node.correctData, &node.possibleData)
+	documents := orderByRelevance(t.documents, intersectPostingList([]*postings{exact, prefix}))
+	if len(documents) > k {
+		documents = documents[:k]
+	}
+	return PhraseCompletion{
+		Documents: documents,
+		Phrases:   completePhrases(node, exact, strings.Join(words[:len(words)-1], " "), k),
+	}
+}
+
+// completePhrases returns the k words under the node that are in more documents together with the exact IDs
+// every word is appended to the typed phrase, when there are no exact IDs every document is considered
+func completePhrases(node *Node, exact *postings, phrase string, k int) []string {
+	counts := make(map[string]int)
+	node.walkWords(func(word *Node) {
+		countPhrase(counts, phrase, word.correctWords, &word.correctData, exact)
+	})
+	return selectTopWords(k, counts)
+}
+
+// countPhrase saves the number of documents of the word that are also in the exact IDs, if there is any
+// the phrase is completed with the form of the word that was inserted more times
+func countPhrase(counts map[string]int, phrase string, words map[string]int, data, exact *postings) {
+	count := data.len()
+	if exact != nil {
+		count = and(&exact.docs, &data.docs).cardinality()
+	}
+	if count > 0 {
+		counts[strings.TrimSpace(phrase+" "+mostInserted(words))] = count
+	}
+}
+
+// walkWords visits the node and every node under it that is the end of a word
+func (t *Node) walkWords(visit func(node *Node)) {
+	if t.isWord {
+		visit(t)
+	}
+	for _, child := range t.children {
+		child.walkWords(visit)
+	}
+}
@@ -0,0 +1,55 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_AutocompletePhrase(t *testing.T) {
+	trieNode := NewNode()
+	trieNode.Add("1", "Direito Penal")
+	trieNode.Add("2", "Direito Penal Militar")
+	trieNode.Add("3", "Direito Penitenciário")
+	trieNode.Add("4", "Processo Penal")
+	trieNode.Add("5", "Direito Civil")
+
+	cases := map[string]struct {
+		input    string
+		k        int
+		expected PhraseCompletion
+	}{
+		"Complete the last word": {"direito pen", 10, PhraseCompletion{
+			Documents: []SearchData{{"1", "Direito Penal"}, {"2", "Direito Penal Militar"}, {"3", "Direito Penitenciário"}},
+			Phrases:   []string{"direito Penal", "direito Penitenciário"},
+		}},
+		"Only the last word": {"pen", 10, PhraseCompletion{
+			Documents: []SearchData{{"1", "Direito Penal"}, {"4", "Processo Penal"}, {"2", "Direito Penal Militar"}, {"3", "Direito Penitenciário"}},
+			Phrases:   []string{"Penal", "Penitenciário"},
+		}},
+		"Limited by k": {"direito pen", 1, PhraseCompletion{
+			Documents: []SearchData{{"1", "Direito Penal"}},
+			Phrases:   []string{"direito Penal"},
+		}},
+		"Small words are kept in the phrase": {"processo de pe", 10, PhraseCompletion{
+			Documents: []SearchData{{"4", "Processo Penal"}},
+			Phrases:   []string{"processo de Penal"},
+		}},
+		"Last word is complete": {"direito civil", 10, PhraseCompletion{
+			Documents: []SearchData{{"5", "Direito Civil"}},
+			Phrases:   []string{"direito Civil"},
+		}},
+		"Complete word not found":   {"direto pen", 10, PhraseCompletion{}},
+		"Prefix of a complete word": {"dir pen", 10, PhraseCompletion{}},
+		"Empty input":               {" ", 10, PhraseCompletion{}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expected, trieNode.AutocompletePhrase(tc.input, tc.k))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
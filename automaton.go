@@ -0,0 +1,249 @@
+package trie
+
+import (
+	"encoding/binary"
+	"sort"
+	"unicode/utf8"
+)
+
+// Size in bytes of a state in the automaton file, the number of words accepted from the state,
+// a flag if the state is final and the number of transitions
+const stateSize = 7
+
+// Size in bytes of a transition in the automaton file, the label, the offset of the target state
+// and the number of words accepted before the transition
+const transitionSize = 9
+
+// automatonState is a state of the automaton while it is built
+type automatonState struct {
+	final   bool
+	labels  []byte
+	targets []*automatonState
+	words   int
+	id      int
+}
+
+// automatonBuilder builds the minimal automaton of words inserted in increasing order
+// the states of the last word are only registered when the next word leaves them, so states
+// with the same continuations are found in the register and shared, like the suffixes of the words
+type automatonBuilder struct {
+	path     []*automatonState
+	previous string
+	register map[string]*automatonState
+	states   []*automatonState
+}
+
+func newAutomatonBuilder() *automatonBuilder {
+	return &automatonBuilder{
+		path:     []*automatonState{{}},
+		register: make(map[string]*automatonState),
+	}
+}
+
+// insert adds the word to the automaton, it must be bigger than the words already inserted
+func (b *automatonBuilder) insert(word string) {
+	common := 0
+	for common < len(word) && common < len(b.previous) && word[common] == b.previous[common] {
+		common++
+	}
+	b.minimize(common)
+	for i := common; i < len(word); i++ {
+		state := &automatonState{}
+		last := b.path[len(b.path)-1]
+		last.labels = append(last.labels, word[i])
+		last.targets = append(last.targets, state)
+		b.path = append(b.path, state)
+	}
+	b.path[len(b.path)-1].final = true
+	b.previous = word
+}
+
+// minimize replaces the states of the previous word after the common prefix by the equivalent registered states
+func (b *automatonBuilder) minimize(common int) {
+	for len(b.path) > common+1 {
+		state := b.path[len(b.path)-1]
+		b.path = b.path[:len(b.path)-1]
+		parent := b.path[len(b.path)-1]
+		parent.targets[len(parent.targets)-1] = b.registered(state)
+	}
+}
+
+// registered returns the state of the register that accepts the same continuations of the state
+// the targets of the state are already registered, so two states are equivalent when they have the same transitions
+func (b *automatonBuilder) registered(state *automatonState) *automatonState {
+	key := make([]byte, 1, 1+len(state.labels)*5)
+	if state.final {
+		key[0] = 1
+	}
+	for i, label := range state.labels {
+		key = append(key, label)
+		key = append(key, byte(state.targets[i].id), byte(state.targets[i].id>>8), byte(state.targets[i].id>>16), byte(state.targets[i].id>>24))
+	}
+	if registered, ok := b.register[string(key)]; ok {
+		return registered
+	}
+	state.id = len(b.states)
+	state.words = state.countWords()
+	b.register[string(key)] = state
+	b.states = append(b.states, state)
+	return state
+}
+
+func (s *automatonState) countWords() int {
+	words := 0
+	if s.final {
+		words++
+	}
+	for _, target := range s.targets {
+		words += target.words
+	}
+	return words
+}
+
+// bytes returns the automaton in the file format and the offset of the root state
+// every state is written after its targets, the root is the last one
+func (b *automatonBuilder) bytes() ([]byte, uint32) {
+	b.minimize(0)
+	root := b.path[0]
+	root.words = root.countWords()
+	states := append(b.states, root)
+	offsets := make(map[*automatonState]uint32, len(states))
+	size := 0
+	for _, state := range states {
+		offsets[state] = uint32(size)
+		size += stateSize + len(state.labels)*transitionSize
+	}
+	data := make([]byte, 0, size)
+	for _, state := range states {
+		var header [stateSize]byte
+		binary.LittleEndian.PutUint32(header[0:], uint32(state.words))
+		if state.final {
+			header[4] = 1
+		}
+		binary.LittleEndian.PutUint16(header[5:], uint16(len(state.labels)))
+		data = append(data, header[:]...)
+		before := 0
+		if state.final {
+			before++
+		}
+		for i, label := range state.labels {
+			var transition [transitionSize]byte
+			transition[0] = label
+			binary.LittleEndian.PutUint32(transition[1:], offsets[state.targets[i]])
+			binary.LittleEndian.PutUint32(transition[5:], uint32(before))
+			data = append(data, transition[:]...)
+			before += state.targets[i].words
+		}
+	}
+	return data, offsets[root]
+}
+
+// automaton reads the states from the bytes of the file, nothing is decoded in advance
+// the ordinal of a word is its position in the sorted words, it is the sum of the words accepted before every transition taken
+type automaton struct {
+	data []byte
+	root uint32
+}
+
+// wordRange is a prefix found in the automaton with the ordinals of the words that start with it
+// when the prefix is a word it is the first one of the range
+type wordRange struct {
+	prefix string
+	state  uint32
+	first  int
+	last   int
+	isWord bool
+}
+
+// valid returns true if the state and its transitions are inside the data
+func (a automaton) valid(state uint32) bool {
+	if uint64(state)+stateSize > uint64(len(a.data)) {
+		return false
+	}
+	return uint64(state)+stateSize+uint64(a.transitions(state))*transitionSize <= uint64(len(a.data))
+}
+
+// validTarget returns true if the target is a valid state written before the state, so a damaged file cannot create a loop
+func (a automaton) validTarget(state, target uint32) bool {
+	return target < state && a.valid(target)
+}
+
+func (a automaton) words(state uint32) int {
+	return int(binary.LittleEndian.Uint32(a.data[state:]))
+}
+
+func (a automaton) final(state uint32) bool {
+	return a.data[state+4] == 1
+}
+
+func (a automaton) transitions(state uint32) int {
+	return int(binary.LittleEndian.Uint16(a.data[state+5:]))
+}
+
+// transition returns the label, the target and the words accepted before the transition i of the state
+func (a automaton) transition(state uint32, i int) (byte, uint32, int) {
+	t := a.data[int(state)+stateSize+i*transitionSize:]
+	return t[0], binary.LittleEndian.Uint32(t[1:]), int(binary.LittleEndian.Uint32(t[5:]))
+}
+
+// next returns the target of the transition with the label, the labels of a state are sorted
+func (a automaton) next(state uint32, label byte) (uint32, int, bool) {
+	n := a.transitions(state)
+	i := sort.Search(n, func(i int) bool {
+		found, _, _ := a.transition(state, i)
+		return found >= label
+	})
+	if i == n {
+		return 0, 0, false
+	}
+	found, target, before := a.transition(state, i)
+	if found != label || !a.validTarget(state, target) {
+		return 0, 0, false
+	}
+	return target, before, true
+}
+
+// seek follows the word from the root and returns the range of the longest prefix of the word that was matched
+// the prefix always ends in a complete rune, as the nodes of the trie
+func (a automaton) seek(word string) wordRange {
+	if !a.valid(a.root) {
+		return wordRange{}
+	}
+	state, ordinal := a.root, 0
+	found := wordRange{state: state, last: a.words(state), isWord: a.final(state)}
+	for i := 0; i < len(word); i++ {
+		target, before, ok := a.next(state, word[i])
+		if !ok {
+			break
+		}
+		state, ordinal = target, ordinal+before
+		if i+1 == len(word) || utf8.RuneStart(word[i+1]) {
+			found = wordRange{prefix: word[:i+1], state: state, first: ordinal, last: ordinal + a.words(state), isWord: a.final(state)}
+		}
+	}
+	return found
+}
+
+// each visits the words of the range in increasing order until the visit returns false
+func (a automaton) each(r wordRange, visit func(word string, ordinal int) bool) {
+	if r.first >= r.last {
+		return
+	}
+	a.walk(r.state, []byte(r.prefix), r.first, visit)
+}
+
+func (a automaton) walk(state uint32, word []byte, ordinal int, visit func(word string, ordinal int) bool) bool {
+	if a.final(state) && !visit(string(word), ordinal) {
+		return false
+	}
+	for i := 0; i < a.transitions(state); i++ {
+		label, target, before := a.transition(state, i)
+		if !a.validTarget(state, target) {
+			continue
+		}
+		if !a.walk(target, append(word, label), ordinal+before, visit) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newAutomaton(words ...string) (automaton, int) {
+	builder := newAutomatonBuilder()
+	for _, word := range words {
+		builder.insert(word)
+	}
+	data, root := builder.bytes()
+	return automaton{data: data, root: root}, len(builder.states) + 1
+}
+
+func Test_AutomatonSharesSuffixes(t *testing.T) {
+	cases := map[string]struct {
+		words    []string
+		expected int
+	}{
+		"Same suffix":           {[]string{"dar", "par"}, 4},
+		"Suffix of other word":  {[]string{"acao", "tributacao"}, 11},
+		"Different middle rune": {[]string{"dar", "dor"}, 4},
+		"Different suffixes":    {[]string{"dar", "dom"}, 5},
+		"Words with the prefix": {[]string{"dir", "direito", "direta"}, 9},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, states := newAutomaton(tc.words...)
+			if states != tc.expected {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.expected, states)
+			}
+		})
+	}
+}
+
+func Test_AutomatonSeek(t *testing.T) {
+	a, _ := newAutomaton("car", "cart", "cat", "dog", "ðat")
+
+	cases := map[string]struct {
+		word     string
+		expected wordRange
+	}{
+		"Prefix of words":      {"ca", wordRange{prefix: "ca", first: 0, last: 3}},
+		"Complete word":        {"cart", wordRange{prefix: "cart", first: 1, last: 2, isWord: true}},
+		"Word with longer one": {"car", wordRange{prefix: "car", first: 0, last: 2, isWord: true}},
+		"Longest prefix":       {"dogs", wordRange{prefix: "dog", first: 3, last: 4, isWord: true}},
+		"Not found":            {"xyz", wordRange{prefix: "", first: 0, last: 5}},
+		"Inside of a rune":     {"ødd", wordRange{prefix: "", first: 0, last: 5}},
+		"Rune with many bytes": {"ða", wordRange{prefix: "ða", first: 4, last: 5}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := a.seek(tc.word)
+			got.state = 0
+			diff := cmp.Diff(tc.expected, got, cmp.AllowUnexported(wordRange{}))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_AutomatonEach(t *testing.T) {
+	a, _ := newAutomaton("car", "cart", "cat", "dog")
+	var words []string
+	var ordinals []int
+	a.each(a.seek("ca"), func(word string, ordinal int) bool {
+		words = append(words, word)
+		ordinals = append(ordinals, ordinal)
+		return len(words) < 2
+	})
+	diff := cmp.Diff([]string{"car", "cart"}, words)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	diff = cmp.Diff([]int{0, 1}, ordinals)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
@@ -0,0 +1,456 @@
+package trie
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Containers with more values than this are kept as bitsets, smaller ones as sorted arrays
+const maxArraySize = 4096
+
+// Number of words of a bitset container, one bit for each of the 65536 low values
+const bitsetSize = 1024
+
+// bitmap is a compressed set of document ordinals in the roaring format
+// the ordinals are split in containers by their 16 high bits, a container keeps the 16 low bits
+// in a sorted array while it is sparse and in a bitset when it is dense
+type bitmap struct {
+	keys       []uint16
+	containers []container
+}
+
+// container keeps the low bits of the values in array or, when it is nil, in bitset
+type container struct {
+	array  []uint16
+	bitset []uint64
+	size   int
+}
+
+// add inserts the value and returns false if it was already in the bitmap
+func (b *bitmap) add(x uint32) bool {
+	key := uint16(x >> 16)
+	i := sort.Search(len(b.keys), func(i int) bool {
+		return b.keys[i] >= key
+	})
+	if i == len(b.keys) || b.keys[i] != key {
+		b.keys = append(b.keys, 0)
+		copy(b.keys[i+1:], b.keys[i:])
+		b.keys[i] = key
+		b.containers = append(b.containers, container{})
+		copy(b.containers[i+1:], b.containers[i:])
+		b.containers[i] = container{}
+	}
+	return b.containers[i].add(uint16(x))
+}
+
+// remove deletes the value and returns false if it was not in the bitmap, an empty container is deleted
+func (b *bitmap) remove(x uint32) bool {
+	key := uint16(x >> 16)
+	i := sort.Search(len(b.keys), func(i int) bool {
+		return b.keys[i] >= key
+	})
+	if i == len(b.keys) || b.keys[i] != key || !b.containers[i].remove(uint16(x)) {
+		return false
+	}
+	if b.containers[i].size == 0 {
+		b.keys = append(b.keys[:i], b.keys[i+1:]...)
+		b.containers = append(b.containers[:i], b.containers[i+1:]...)
+	}
+	return true
+}
+
+// contains returns true if the value is in the bitmap
+func (b *bitmap) contains(x uint32) bool {
+	c := b.container(uint16(x >> 16))
+	return c != nil && c.contains(uint16(x))
+}
+
+// container returns the container of the high bits or nil if there is none
+func (b *bitmap) container(key uint16) *container {
+	i := sort.Search(len(b.keys), func(i int) bool {
+		return b.keys[i] >= key
+	})
+	if i < len(b.keys) && b.keys[i] == key {
+		return &b.containers[i]
+	}
+	return nil
+}
+
+// cardinality returns the number of values in the bitmap
+func (b *bitmap) cardinality() int {
+	size := 0
+	for i := range b.containers {
+		size += b.containers[i].size
+	}
+	return size
+}
+
+// rank returns the number of values in the bitmap that are smaller or equal to x
+func (b *bitmap) rank(x uint32) int {
+	key := uint16(x >> 16)
+	size := 0
+	for i, k := range b.keys {
+		if k > key {
+			break
+		}
+		if k < key {
+			size += b.containers[i].size
+			continue
+		}
+		size += b.containers[i].rank(uint16(x))
+	}
+	return size
+}
+
+// last returns the biggest value of the bitmap, it is false if the bitmap is empty
+func (b *bitmap) last() (uint32, bool) {
+	if len(b.keys) == 0 {
+		return 0, false
+	}
+	last := len(b.keys) - 1
+	return uint32(b.keys[last])<<16 | uint32(b.containers[last].last()), true
+}
+
+// each calls the function for every value in increasing order until it returns false
+func (b *bitmap) each(f func(x uint32) bool) {
+	for i := range b.containers {
+		high := uint32(b.keys[i]) << 16
+		if !b.containers[i].each(func(low uint16) bool {
+			return f(high | uint32(low))
+		}) {
+			return
+		}
+	}
+}
+
+// bitmapCursor returns the index of the values of a bitmap, the values must be asked in increasing order
+// so the values before them are counted only once
+type bitmapCursor struct {
+	b        *bitmap
+	i        int
+	base     int
+	word     int
+	wordBase int
+}
+
+func (b *bitmap) cursor() *bitmapCursor {
+	return &bitmapCursor{b: b}
+}
+
+// index returns the index of the value in the bitmap, the value must be in the bitmap
+func (c *bitmapCursor) index(x uint32) int {
+	key := uint16(x >> 16)
+	for c.b.keys[c.i] < key {
+		c.base += c.b.containers[c.i].size
+		c.i++
+		c.word = 0
+		c.wordBase = 0
+	}
+	current := &c.b.containers[c.i]
+	low := uint16(x)
+	if current.bitset == nil {
+		return c.base + sort.Search(len(current.array), func(i int) bool {
+			return current.array[i] >= low
+		})
+	}
+	for c.word < int(low/64) {
+		c.wordBase += bits.OnesCount64(current.bitset[c.word])
+		c.word++
+	}
+	return c.base + c.wordBase + bits.OnesCount64(current.bitset[c.word]<<(63-low%64)) - 1
+}
+
+// and returns the values that are in both bitmaps
+func and(a, b *bitmap) *bitmap {
+	result := &bitmap{}
+	for i, j := 0, 0; i < len(a.keys) && j < len(b.keys); {
+		switch {
+		case a.keys[i] < b.keys[j]:
+			i++
+		case a.keys[i] > b.keys[j]:
+			j++
+		default:
+			if c := andContainers(&a.containers[i], &b.containers[j]); c.size > 0 {
+				result.keys = append(result.keys, a.keys[i])
+				result.containers = append(result.containers, c)
+			}
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// or returns the values that are in any of the bitmaps
+func or(a, b *bitmap) *bitmap {
+	result := &bitmap{}
+	i, j := 0, 0
+	for i < len(a.keys) || j < len(b.keys) {
+		switch {
+		case j == len(b.keys) || (i < len(a.keys) && a.keys[i] < b.keys[j]):
+			result.keys = append(result.keys, a.keys[i])
+			result.containers = append(result.containers, a.containers[i].clone())
+			i++
+		case i == len(a.keys) || b.keys[j] < a.keys[i]:
+			result.keys = append(result.keys, b.keys[j])
+			result.containers = append(result.containers, b.containers[j].clone())
+			j++
+		default:
+			result.keys = append(result.keys, a.keys[i])
+			result.containers = append(result.containers, orContainers(&a.containers[i], &b.containers[j]))
+			i++
+			j++
+		}
+	}
+	return result
+}
+
+// andNot returns the values of the first bitmap that are not in the second one
+func andNot(a, b *bitmap) *bitmap {
+	result := &bitmap{}
+	for i := range a.keys {
+		c := a.containers[i].clone()
+		if other := b.container(a.keys[i]); other != nil {
+			c = andNotContainers(&a.containers[i], other)
+		}
+		if c.size > 0 {
+			result.keys = append(result.keys, a.keys[i])
+			result.containers = append(result.containers, c)
+		}
+	}
+	return result
+}
+
+func (c *container) add(low uint16) bool {
+	if c.bitset != nil {
+		if c.bitset[low/64]&(1<<(low%64)) != 0 {
+			return false
+		}
+		c.bitset[low/64] |= 1 << (low % 64)
+		c.size++
+		return true
+	}
+	i := sort.Search(len(c.array), func(i int) bool {
+		return c.array[i] >= low
+	})
+	if i < len(c.array) && c.array[i] == low {
+		return false
+	}
+	c.array = append(c.array, 0)
+	copy(c.array[i+1:], c.array[i:])
+	c.array[i] = low
+	c.size++
+	if c.size > maxArraySize {
+		c.toBitset()
+	}
+	return true
+}
+
+// remove deletes the low bits, a bitset that becomes small enough is kept as an array again
+func (c *container) remove(low uint16) bool {
+	if !c.contains(low) {
+		return false
+	}
+	c.size--
+	if c.bitset != nil {
+		c.bitset[low/64] &^= 1 << (low % 64)
+		*c = c.normalize()
+		return true
+	}
+	i := sort.Search(len(c.array), func(i int) bool {
+		return c.array[i] >= low
+	})
+	c.array = append(c.array[:i], c.array[i+1:]...)
+	return true
+}
+
+func (c *container) contains(low uint16) bool {
+	if c.bitset != nil {
+		return c.bitset[low/64]&(1<<(low%64)) != 0
+	}
+	i := sort.Search(len(c.array), func(i int) bool {
+		return c.array[i] >= low
+	})
+	return i < len(c.array) && c.array[i] == low
+}
+
+// rank returns the number of values in the container that are smaller or equal to low
+func (c *container) rank(low uint16) int {
+	if c.bitset != nil {
+		size := 0
+		for _, word := range c.bitset[:low/64] {
+			size += bits.OnesCount64(word)
+		}
+		return size + bits.OnesCount64(c.bitset[low/64]<<(63-low%64))
+	}
+	return sort.Search(len(c.array), func(i int) bool {
+		return c.array[i] > low
+	})
+}
+
+func (c *container) last() uint16 {
+	if c.bitset == nil {
+		return c.array[len(c.array)-1]
+	}
+	for i := len(c.bitset) - 1; i >= 0; i-- {
+		if c.bitset[i] != 0 {
+			return uint16(i*64 + 63 - bits.LeadingZeros64(c.bitset[i]))
+		}
+	}
+	return 0
+}
+
+func (c *container) each(f func(low uint16) bool) bool {
+	if c.bitset == nil {
+		for _, low := range c.array {
+			if !f(low) {
+				return false
+			}
+		}
+		return true
+	}
+	for i, word := range c.bitset {
+		for word != 0 {
+			if !f(uint16(i*64 + bits.TrailingZeros64(word))) {
+				return false
+			}
+			word &= word - 1
+		}
+	}
+	return true
+}
+
+func (c *container) clone() container {
+	clone := container{size: c.size}
+	if c.bitset != nil {
+		clone.bitset = append([]uint64(nil), c.bitset...)
+	} else {
+		clone.array = append([]uint16(nil), c.array...)
+	}
+	return clone
+}
+
+func (c *container) toBitset() {
+	c.bitset = make([]uint64, bitsetSize)
+	for _, low := range c.array {
+		c.bitset[low/64] |= 1 << (low % 64)
+	}
+	c.array = nil
+}
+
+// normalize keeps the container as an array when its size allows
+func (c container) normalize() container {
+	if c.bitset == nil || c.size > maxArraySize {
+		return c
+	}
+	array := make([]uint16, 0, c.size)
+	c.each(func(low uint16) bool {
+		array = append(array, low)
+		return true
+	})
+	return container{array: array, size: c.size}
+}
+
+func andContainers(a, b *container) container {
+	switch {
+	case a.bitset != nil && b.bitset != nil:
+		result := container{bitset: make([]uint64, bitsetSize)}
+		for i := range result.bitset {
+			result.bitset[i] = a.bitset[i] & b.bitset[i]
+			result.size += bits.OnesCount64(result.bitset[i])
+		}
+		return result.normalize()
+	case a.bitset != nil:
+		return filterContainer(b, a, true)
+	case b.bitset != nil:
+		return filterContainer(a, b, true)
+	}
+	result := container{}
+	for i, j := 0, 0; i < len(a.array) && j < len(b.array); {
+		switch {
+		case a.array[i] < b.array[j]:
+			i++
+		case a.array[i] > b.array[j]:
+			j++
+		default:
+			result.array = append(result.array, a.array[i])
+			i++
+			j++
+		}
+	}
+	result.size = len(result.array)
+	return result
+}
+
+func orContainers(a, b *container) container {
+	if a.bitset == nil && b.bitset == nil && a.size+b.size <= maxArraySize {
+		result := container{array: make([]uint16, 0, a.size+b.size)}
+		i, j := 0, 0
+		for i < len(a.array) || j < len(b.array) {
+			switch {
+			case j == len(b.array) || (i < len(a.array) && a.array[i] < b.array[j]):
+				result.array = append(result.array, a.array[i])
+				i++
+			case i == len(a.array) || b.array[j] < a.array[i]:
+				result.array = append(result.array, b.array[j])
+				j++
+			default:
+				result.array = append(result.array, a.array[i])
+				i++
+				j++
+			}
+		}
+		result.size = len(result.array)
+		return result
+	}
+	result := container{bitset: make([]uint64, bitsetSize)}
+	for _, c := range []*container{a, b} {
+		if c.bitset != nil {
+			for i, word := range c.bitset {
+				result.bitset[i] |= word
+			}
+			continue
+		}
+		for _, low := range c.array {
+			result.bitset[low/64] |= 1 << (low % 64)
+		}
+	}
+	for _, word := range result.bitset {
+		result.size += bits.OnesCount64(word)
+	}
+	return result.normalize()
+}
+
+func andNotContainers(a, b *container) container {
+	if a.bitset == nil {
+		return filterContainer(a, b, false)
+	}
+	result := a.clone()
+	if b.bitset != nil {
+		result.size = 0
+		for i := range result.bitset {
+			result.bitset[i] &^= b.bitset[i]
+			result.size += bits.OnesCount64(result.bitset[i])
+		}
+		return result.normalize()
+	}
+	for _, low := range b.array {
+		if result.bitset[low/64]&(1<<(low%64)) != 0 {
+			result.bitset[low/64] &^= 1 << (low % 64)
+			result.size--
+		}
+	}
+	return result.normalize()
+}
+
+// filterContainer returns the values of the array container that are, or are not, in the other container
+func filterContainer(array, other *container, keep bool) container {
+	result := container{}
+	for _, low := range array.array {
+		if other.contains(low) == keep {
+			result.array = append(result.array, low)
+		}
+	}
+	result.size = len(result.array)
+	return result
+}
@@ -0,0 +1,109 @@
+package trie
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newBitmap(values ...uint32) *bitmap {
+	b := &bitmap{}
+	for _, x := range values {
+		b.add(x)
+	}
+	return b
+}
+
+func bitmapValues(b *bitmap) []uint32 {
+	var values []uint32
+	b.each(func(x uint32) bool {
+		values = append(values, x)
+		return true
+	})
+	return values
+}
+
+// randomValues returns sorted values without repetition, the density selects if the containers are arrays or bitsets
+func randomValues(rng *rand.Rand, size int, max uint32) []uint32 {
+	set := make(map[uint32]bool)
+	for len(set) < size {
+		set[uint32(rng.Int63n(int64(max)))] = true
+	}
+	var values []uint32
+	for x := range set {
+		values = append(values, x)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+	return values
+}
+
+func Test_BitmapOperations(t *testing.T) {
+	cases := map[string]struct {
+		sizeA, sizeB int
+		max          uint32
+	}{
+		"Arrays":              {100, 200, 1 << 18},
+		"Bitsets":             {50000, 60000, 1 << 17},
+		"Array and bitset":    {1000, 60000, 1 << 17},
+		"Different high bits": {1000, 1000, 1 << 24},
+		"Empty bitmap":        {0, 1000, 1 << 17},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(int64(tc.sizeA + tc.sizeB)))
+			valuesA, valuesB := randomValues(rng, tc.sizeA, tc.max), randomValues(rng, tc.sizeB, tc.max)
+			a, b := newBitmap(valuesA...), newBitmap(valuesB...)
+			inB := make(map[uint32]bool)
+			for _, x := range valuesB {
+				inB[x] = true
+			}
+			var expectedAnd, expectedAndNot []uint32
+			expectedOr := append([]uint32(nil), valuesB...)
+			for _, x := range valuesA {
+				if inB[x] {
+					expectedAnd = append(expectedAnd, x)
+				} else {
+					expectedAndNot = append(expectedAndNot, x)
+					expectedOr = append(expectedOr, x)
+				}
+			}
+			sort.Slice(expectedOr, func(i, j int) bool {
+				return expectedOr[i] < expectedOr[j]
+			})
+
+			diff := cmp.Diff(valuesA, bitmapValues(a))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			diff = cmp.Diff(expectedAnd, bitmapValues(and(a, b)))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			diff = cmp.Diff(expectedOr, bitmapValues(or(a, b)))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			diff = cmp.Diff(expectedAndNot, bitmapValues(andNot(a, b)))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			if and(a, b).cardinality() != len(expectedAnd) {
+				t.Fatalf("\nExpected: %v\nGot: %v", len(expectedAnd), and(a, b).cardinality())
+			}
+			cursor := b.cursor()
+			for i, x := range valuesB {
+				if b.rank(x) != i+1 || cursor.index(x) != i || !b.contains(x) {
+					t.Fatalf("\nExpected: index %v of %v\nGot: rank %v and cursor %v", i, x, b.rank(x), cursor.index(x))
+				}
+			}
+			if last, ok := b.last(); !ok || last != valuesB[len(valuesB)-1] {
+				t.Fatalf("\nExpected: %v\nGot: %v", valuesB[len(valuesB)-1], last)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package trie
+
+import (
+	"runtime"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// BulkLoader adds many documents to a trie, the names are cleaned by workers in parallel and the words that start with
+// different runes are inserted in parallel, in the same order they were given, so the trie is the same built by Add
+// The trie must not be used until Close returns
+type BulkLoader struct {
+	trie      *Node
+	remove    []string
+	inserters int
+	batch     []Document
+	sequence  int
+	batches   chan bulkBatch
+	analyzed  chan bulkBatch
+	workers   sync.WaitGroup
+	merged    chan struct{}
+}
+
+// bulkBatch is a sequence of documents and their words, the number orders the batches cleaned by different workers
+type bulkBatch struct {
+	number int
+	docs   []Document
+	words  [][]analyzedWord
+	took   time.Duration
+}
+
+// NewBulkLoader returns a loader that adds the documents to the trie, the remove list is the same of Add
+// workers is the number of goroutines that clean the names and insert the words of a batch, zero uses one for every processor
+func NewBulkLoader(t *Node, workers int, remove ...string) *BulkLoader {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	l := &BulkLoader{
+		trie:      t,
+		remove:    remove,
+		inserters: workers,
+		batches:   make(chan bulkBatch, workers),
+		analyzed:  make(chan bulkBatch, workers),
+		merged:    make(chan struct{}),
+	}
+	l.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go l.analyze()
+	}
+	go l.merge()
+	return l
+}
+
+// Add queues the document, it is inserted in the trie after its batch is cleaned
+func (l *BulkLoader) Add(id, name string) {
+	if l.batch == nil {
+		l.batch = make([]Document, 0, bulkBatchSize)
+	}
+	l.batch = append(l.batch, Document{ID: id, Name: name})
+	if len(l.batch) == bulkBatchSize {
+		l.flush()
+	}
+}
+
+// Close inserts the documents left and waits until every document is in the trie, the loader must not be used after it
+func (l *BulkLoader) Close() {
+	l.flush()
+	close(l.batches)
+	l.workers.Wait()
+	close(l.analyzed)
+	<-l.merged
+}
+
+func (l *BulkLoader) flush() {
+	if len(l.batch) == 0 {
+		return
+	}
+	l.batches <- bulkBatch{number: l.sequence, docs: l.batch}
+	l.sequence++
+	l.batch = nil
+}
+
+// analyze cleans the names of the batches until there is no batch left
+func (l *BulkLoader) analyze() {
+	defer l.workers.Done()
+	for batch := range l.batches {
+		start := time.Now()
+		batch.words = make([][]analyzedWord, len(batch.docs))
+		for i, doc := range batch.docs {
+			batch.words[i] = analyze(doc.Name, l.remove)
+		}
+		batch.took = time.Since(start)
+		l.analyzed <- batch
+	}
+}
+
+// merge inserts the batches in the trie in the order they were added, the batches cleaned before their turn wait in pending
+func (l *BulkLoader) merge() {
+	defer close(l.merged)
+	pending := make(map[int]bulkBatch)
+	next := 0
+	for batch := range l.analyzed {
+		pending[batch.number] = batch
+		for {
+			batch, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			l.insert(batch)
+			next++
+		}
+	}
+}
+
+// insert adds the documents of the batch, the ordinals are created first and then the words are split by the trie and the
+// first rune they start with, the words of every subtree are inserted by a worker, as they only change the nodes under its child
+// the observer receives the same share of the time of the batch for every document
+func (l *BulkLoader) insert(batch bulkBatch) {
+	t := l.trie
+	start := time.Now()
+	documents := make([]int, len(batch.docs))
+	subtrees := bulkSubtrees{index: make(map[bulkSubtreeKey]int)}
+	for i, doc := range batch.docs {
+		if words := batch.words[i]; len(words) > 0 {
+			ordinal := t.documents.ordinal(doc.ID, doc.Name)
+			t.documents.addWords(ordinal, joinWords(words))
+			if t.suffixes == nil {
+				t.suffixes = &Node{children: make(map[rune]*Node)}
+			}
+			for _, w := range words {
+				subtrees.add(t, false, bulkWord{doc: ordinal, word: w.word, cleaned: w.cleaned, position: w.position})
+				eachSuffix(w.cleaned, func(suffix string) {
+					subtrees.add(t.suffixes, true, bulkWord{doc: ordinal, word: w.word, cleaned: suffix, position: w.position})
+				})
+			}
+		}
+		documents[i] = len(t.documents.ordinals)
+	}
+	subtrees.insert(l.inserters)
+	if t.observer == nil {
+		return
+	}
+	took := (time.Since(start) + batch.took) / time.Duration(len(batch.docs))
+	for i, words := range batch.words {
+		t.observer.ObserveAdd(len(words), documents[i], took)
+	}
+}
+
+// bulkWord is a word or a suffix of a document of a batch
+type bulkWord struct {
+	doc      uint32
+	word     string
+	cleaned  string
+	position int
+}
+
+// bulkSubtreeKey is the trie, the words or the suffixes, and the first rune of the words of a subtree
+type bulkSubtreeKey struct {
+	suffix bool
+	first  rune
+}
+
+// bulkSubtree is the words of a batch inserted under the child of the first rune of the root, in the order they were added
+type bulkSubtree struct {
+	root  *Node
+	first rune
+	words []bulkWord
+	child *Node
+}
+
+// bulkSubtrees are the subtrees of a batch in the order their first word was added
+type bulkSubtrees struct {
+	index    map[bulkSubtreeKey]int
+	subtrees []*bulkSubtree
+}
+
+func (s *bulkSubtrees) add(root *Node, suffix bool, w bulkWord) {
+	first, _ := utf8.DecodeRuneInString(w.cleaned)
+	key := bulkSubtreeKey{suffix: suffix, first: first}
+	i, ok := s.index[key]
+	if !ok {
+		i = len(s.subtrees)
+		s.index[key] = i
+		s.subtrees = append(s.subtrees, &bulkSubtree{root: root, first: first})
+	}
+	s.subtrees[i].words = append(s.subtrees[i].words, w)
+}
+
+// insert inserts the subtrees in the workers and then sets the child of every subtree in its root
+// the roots are only read while the workers run, so only the children of different subtrees are changed at the same time
+func (s *bulkSubtrees) insert(workers int) {
+	subtrees := make(chan *bulkSubtree)
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(s.subtrees); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for subtree := range subtrees {
+				subtree.insert()
+			}
+		}()
+	}
+	for _, subtree := range s.subtrees {
+		subtrees <- subtree
+	}
+	close(subtrees)
+	wg.Wait()
+	for _, subtree := range s.subtrees {
+		subtree.root.children[subtree.first] = subtree.child
+	}
+}
+
+// insert adds the words under a root of its own that only has the child of the first rune, so the root is not changed
+func (s *bulkSubtree) insert() {
+	root := &Node{children: make(map[rune]*Node, 1)}
+	if child, ok := s.root.children[s.first]; ok {
+		root.children[s.first] = child
+	}
+	for _, w := range s.words {
+		root.insert(w.doc, w.word, w.cleaned, w.position)
+	}
+	s.child = root.children[s.first]
+}
+
+// AddBatch adds the documents in the same way as Add, but the names are cleaned and the words are inserted in parallel by a BulkLoader
+func (t *Node) AddBatch(docs []Document, remove ...string) {
+	loader := NewBulkLoader(t, 0, remove...)
+	for _, doc := range docs {
+		loader.Add(doc.ID, doc.Name)
+	}
+	loader.Close()
+}
@@ -0,0 +1,108 @@
+package trie
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// trieContent returns every word of the trie with its IDs, and the IDs and names found by a search of every syllable
+func trieContent(t *Node) []string {
+	var content []string
+	t.WalkPrefix("", func(word string, ids []string) bool {
+		content = append(content, fmt.Sprint(word, ids))
+		return true
+	})
+	for _, syllable := range syllables {
+		content = append(content, fmt.Sprint(syllable, t.SearchByRelevance(syllable), t.SearchInfix(syllable)))
+	}
+	return content
+}
+
+func Test_AddBatch(t *testing.T) {
+	cases := map[string]struct {
+		docs   []Document
+		remove []string
+	}{
+		"Synthetic documents": {syntheticDocuments(4000), nil},
+		"Repeated IDs and short names": {[]Document{
+			{"1", "Direito Penal"}, {"2", "de"}, {"1", "Direito Civil"}, {"3", "Direito-Administrativo"}, {"2", "Direção Defensiva"},
+		}, []string{"-"}},
+		"No documents": {nil, nil},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			expected := NewNode()
+			for _, doc := range tc.docs {
+				expected.Add(doc.ID, doc.Name, tc.remove...)
+			}
+			batch := NewNode()
+			batch.AddBatch(tc.docs, tc.remove...)
+			diff := cmp.Diff(trieContent(expected), trieContent(batch))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			diff = cmp.Diff(expected.Stats(), batch.Stats(), cmpopts.IgnoreFields(Stats{}, "HeapSize"))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_BulkLoader(t *testing.T) {
+	docs := syntheticDocuments(4000)
+	expected := NewNode()
+	for _, doc := range docs {
+		expected.Add(doc.ID, doc.Name)
+	}
+	for _, workers := range []int{1, 3, 16} {
+		t.Run(fmt.Sprintf("workers=%d", workers), func(t *testing.T) {
+			trieNode := NewNode()
+			trieNode.Add("first", "Documento Anterior")
+			observer := &recorder{}
+			trieNode.SetObserver(observer)
+			loader := NewBulkLoader(trieNode, workers)
+			for _, doc := range docs {
+				loader.Add(doc.ID, doc.Name)
+			}
+			loader.Close()
+			trieNode.SetObserver(nil)
+			if len(observer.calls) != len(docs) {
+				t.Fatalf("\nExpected: %v\nGot: %v", len(docs), len(observer.calls))
+			}
+			trieNode.Remove("first")
+			diff := cmp.Diff(trieContent(expected), trieContent(trieNode))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Benchmark_AddBatch(b *testing.B) {
+	for _, words := range []int{10000, 100000} {
+		docs := syntheticDocuments(words)
+		b.Run(fmt.Sprintf("sequential/words=%d", words), func(b *testing.B) {
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				trieNode := NewNode()
+				for _, doc := range docs {
+					trieNode.Add(doc.ID, doc.Name)
+				}
+			}
+			b.ReportMetric(float64(len(docs)*b.N)/time.Since(start).Seconds(), "docs/s")
+		})
+		b.Run(fmt.Sprintf("batch/words=%d", words), func(b *testing.B) {
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				NewNode().AddBatch(docs)
+			}
+			b.ReportMetric(float64(len(docs)*b.N)/time.Since(start).Seconds(), "docs/s")
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Command trie-server serves a trie over HTTP with JSON requests and responses
+//
+//	POST   /documents         {"id": "1", "name": "Direito Penal"} indexes or replaces a document
+//	DELETE /documents/{id}    removes a document
+//	GET    /search?q=&page=&per_page=&facets= returns a page of the documents ordered by relevance
+//	GET    /autocomplete?q=&k= returns the best documents and phrases for a phrase being typed
+//	GET    /metrics           returns the counters and histograms of the trie in the Prometheus text format
+//
+// When a snapshot file is given it is loaded on start, if it exists, and saved when the server stops
+// When a log file is also given every change is written in it before it is applied, the changes after the snapshot are
+// replayed on start, so they are not lost if the server stops without saving, and the log is compacted in the snapshot on stop
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Time the requests being served have to finish after the server is asked to stop
+const shutdownTimeout = 10 * time.Second
+
+// removeFlag is a pattern removed from the names of the documents, the flag may be repeated
+type removeFlag []string
+
+func (f *removeFlag) String() string { return strings.Join(*f, " ") }
+func (f *removeFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	snapshot := flag.String("snapshot", "", "index file loaded on start and saved on stop")
+	logFile := flag.String("log", "", "log of the changes after the snapshot, it needs the snapshot")
+	var remove removeFlag
+	flag.Var(&remove, "remove", "pattern removed from the names of the documents, it may be repeated")
+	flag.Parse()
+	if *logFile != "" && *snapshot == "" {
+		log.Fatal("the log needs a snapshot file")
+	}
+	if err := run(*addr, *snapshot, *logFile, remove); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run serves the trie until an interrupt or terminate signal, then waits for the requests and saves the snapshot
+func run(addr, snapshot, logFile string, remove []string) error {
+	s, err := openServer(snapshot, logFile, remove)
+	if err != nil {
+		return err
+	}
+	httpServer := &http.Server{Addr: addr, Handler: s.routes()}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+	log.Printf("listening on %s", addr)
+	select {
+	case err := <-errs:
+		return err
+	case <-stop:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return s.close(snapshot)
+}
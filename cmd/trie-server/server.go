@@ -0,0 +1,343 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"trie-go/trie"
+	"trie-go/trie/metrics"
+)
+
+// Default values of the query parameters
+const (
+	defaultPerPage = 10
+	maxPerPage     = 100
+	defaultK       = 10
+)
+
+// server exposes a trie over HTTP
+// when the server has a durable trie, the changes are made by it, so they are written in its log
+type server struct {
+	mu      sync.RWMutex
+	trie    *trie.Node
+	durable *trie.DurableNode
+	remove  []string
+	metrics *metrics.Collector
+}
+
+type document struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type hit struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Score     float64  `json:"score"`
+	Terms     []string `json:"terms"`
+	Positions []int    `json:"positions"`
+	Match     string   `json:"match"`
+}
+
+type facet struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+type searchResponse struct {
+	Hits    []hit              `json:"hits"`
+	Total   int                `json:"total"`
+	Page    int                `json:"page"`
+	PerPage int                `json:"per_page"`
+	TookMS  float64            `json:"took_ms"`
+	Facets  map[string][]facet `json:"facets,omitempty"`
+}
+
+type autocompleteResponse struct {
+	Documents []document `json:"documents"`
+	Phrases   []string   `json:"phrases"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// newServer returns the server of the trie, the collector of the metrics is set as the observer of the trie
+func newServer(t *trie.Node, remove []string) *server {
+	collector := metrics.NewCollector()
+	collector.SetDocuments(t.Stats().Documents)
+	t.SetObserver(collector)
+	return &server{trie: t, remove: remove, metrics: collector}
+}
+
+// openServer returns the server of the trie of the snapshot, the changes of the log are replayed when there is one
+func openServer(snapshot, logFile string, remove []string) (*server, error) {
+	if logFile == "" {
+		t, err := loadSnapshot(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		return newServer(t, remove), nil
+	}
+	durable, err := trie.OpenDurable(snapshot, logFile)
+	if err != nil {
+		return nil, err
+	}
+	s := newServer(durable.Trie(), remove)
+	s.durable = durable
+	return s, nil
+}
+
+// routes returns the handler of every endpoint, an unknown path returns a JSON error
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/documents", s.handleAdd)
+	mux.HandleFunc("/documents/", s.handleDelete)
+	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/autocomplete", s.handleAutocomplete)
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if allowMethod(w, r, http.MethodGet) {
+			s.metrics.ServeHTTP(w, r)
+		}
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not found")
+	})
+	return mux
+}
+
+// handleAdd indexes the document of the body, a document with the same ID is replaced
+func (s *server) handleAdd(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+	var doc document
+	if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid document: "+err.Error())
+		return
+	}
+	if doc.ID == "" || strings.TrimSpace(doc.Name) == "" {
+		writeError(w, http.StatusBadRequest, "the document must have an id and a name")
+		return
+	}
+	s.mu.Lock()
+	replaced, err := s.replace(doc)
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	status := http.StatusCreated
+	if replaced {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, doc)
+}
+
+// handleDelete removes the document of the ID in the path
+func (s *server) handleDelete(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodDelete) {
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/documents/")
+	s.mu.Lock()
+	removed, err := s.delete(id)
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !removed {
+		writeError(w, http.StatusNotFound, "document not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// replace removes the document with the same ID and adds the document, it returns true if the ID was in the trie
+func (s *server) replace(doc document) (bool, error) {
+	if s.durable != nil {
+		return s.durable.Update(doc.ID, doc.Name, s.remove...)
+	}
+	replaced := s.trie.Remove(doc.ID)
+	s.trie.Add(doc.ID, doc.Name, s.remove...)
+	return replaced, nil
+}
+
+// delete removes the document of the ID, it returns false if the ID is not in the trie
+func (s *server) delete(id string) (bool, error) {
+	if id == "" {
+		return false, nil
+	}
+	if s.durable != nil {
+		return s.durable.Remove(id)
+	}
+	return s.trie.Remove(id), nil
+}
+
+// handleSearch returns a page of the documents of the phrase q ordered by relevance
+func (s *server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	query := r.URL.Query()
+	phrase := query.Get("q")
+	if strings.TrimSpace(phrase) == "" {
+		writeError(w, http.StatusBadRequest, "missing the q parameter")
+		return
+	}
+	page, ok := intParameter(w, query.Get("page"), "page", 1, 1, 0)
+	if !ok {
+		return
+	}
+	perPage, ok := intParameter(w, query.Get("per_page"), "per_page", defaultPerPage, 1, maxPerPage)
+	if !ok {
+		return
+	}
+	facets, ok := intParameter(w, query.Get("facets"), "facets", 0, 0, maxPerPage)
+	if !ok {
+		return
+	}
+	opts := trie.SearchOptions{Pagination: &trie.Pagination{PerPage: int32(perPage), Page: int32(page)}, Facets: facets}
+	s.mu.RLock()
+	result, err := s.trie.SearchContext(r.Context(), phrase, opts)
+	s.mu.RUnlock()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	response := searchResponse{
+		Hits:    make([]hit, 0, len(result.Hits)),
+		Total:   result.Total,
+		Page:    page,
+		PerPage: perPage,
+		TookMS:  float64(result.Took) / float64(time.Millisecond),
+	}
+	for _, h := range result.Hits {
+		response.Hits = append(response.Hits, hit{ID: h.ID, Name: h.Name, Score: h.Score, Terms: h.Terms, Positions: h.Positions, Match: h.MatchType.String()})
+	}
+	for term, counts := range result.Facets {
+		if response.Facets == nil {
+			response.Facets = make(map[string][]facet)
+		}
+		for _, count := range counts {
+			response.Facets[term] = append(response.Facets[term], facet{Word: count.Word, Count: count.Count})
+		}
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// handleAutocomplete returns the k best documents and phrases for the phrase q that is being typed
+func (s *server) handleAutocomplete(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+	query := r.URL.Query()
+	k, ok := intParameter(w, query.Get("k"), "k", defaultK, 1, maxPerPage)
+	if !ok {
+		return
+	}
+	s.mu.RLock()
+	completion := s.trie.AutocompletePhrase(query.Get("q"), k)
+	s.mu.RUnlock()
+	response := autocompleteResponse{Documents: make([]document, 0, len(completion.Documents)), Phrases: make([]string, 0, len(completion.Phrases))}
+	for _, data := range completion.Documents {
+		response.Documents = append(response.Documents, document{ID: data.ID, Name: data.Name})
+	}
+	response.Phrases = append(response.Phrases, completion.Phrases...)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// close saves the snapshot when the server stops, a durable trie compacts its log in the snapshot and closes it
+func (s *server) close(snapshot string) error {
+	if s.durable == nil {
+		if snapshot == "" {
+			return nil
+		}
+		return s.save(snapshot)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.durable.Compact(); err != nil {
+		s.durable.Close()
+		return err
+	}
+	return s.durable.Close()
+}
+
+// save writes the trie in the snapshot file, the file is only replaced after it is completely written
+func (s *server) save(path string) error {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	s.mu.RLock()
+	err = s.trie.WriteIndex(file)
+	s.mu.RUnlock()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+// loadSnapshot returns the trie of the snapshot file, a new trie is returned when there is no file yet
+func loadSnapshot(path string) (*trie.Node, error) {
+	if path == "" {
+		return trie.NewNode(), nil
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return trie.NewNode(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return trie.ReadNode(file)
+}
+
+// allowMethod writes a JSON error if the method of the request is not the method of the endpoint
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+// intParameter returns the value of an integer parameter or its default when it is empty, zero max has no maximum
+// a JSON error is written when the value is not an integer or is out of the bounds
+func intParameter(w http.ResponseWriter, value, name string, defaultValue, min, max int) (int, bool) {
+	if value == "" {
+		return defaultValue, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || (max > 0 && n > max) {
+		writeError(w, http.StatusBadRequest, "invalid "+name+" parameter: "+value)
+		return 0, false
+	}
+	return n, true
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"trie-go/trie"
+)
+
+func testServer() *server {
+	t := trie.NewNode()
+	t.Add("1", "Direito Penal")
+	t.Add("2", "Direito Civil")
+	t.Add("3", "Direção Defensiva")
+	t.Add("4", "Administração Pública")
+	return newServer(t, []string{"-"})
+}
+
+func request(t *testing.T, handler http.Handler, method, target, body string) (int, string) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+	if recorder.Code != http.StatusNoContent && recorder.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("\nExpected: %v\nGot: %v", "application/json", recorder.Header().Get("Content-Type"))
+	}
+	return recorder.Code, strings.TrimSpace(recorder.Body.String())
+}
+
+func Test_Endpoints(t *testing.T) {
+	cases := map[string]struct {
+		method, target, body string
+		status               int
+		expected             string
+	}{
+		"Add":                    {"POST", "/documents", `{"id": "5", "name": "Direito-Tributário"}`, http.StatusCreated, `{"id":"5","name":"Direito-Tributário"}`},
+		"Replace":                {"POST", "/documents", `{"id": "1", "name": "Penal"}`, http.StatusOK, `{"id":"1","name":"Penal"}`},
+		"Add invalid JSON":       {"POST", "/documents", `{"id": `, http.StatusBadRequest, `{"error":"invalid document: unexpected EOF"}`},
+		"Add without name":       {"POST", "/documents", `{"id": "5"}`, http.StatusBadRequest, `{"error":"the document must have an id and a name"}`},
+		"Add with wrong method":  {"GET", "/documents", ``, http.StatusMethodNotAllowed, `{"error":"method not allowed"}`},
+		"Delete":                 {"DELETE", "/documents/2", ``, http.StatusNoContent, ``},
+		"Delete not found":       {"DELETE", "/documents/9", ``, http.StatusNotFound, `{"error":"document not found"}`},
+		"Delete without ID":      {"DELETE", "/documents/", ``, http.StatusNotFound, `{"error":"document not found"}`},
+		"Search":                 {"GET", "/search?q=direito", ``, http.StatusOK, `{"hits":[{"id":"2","name":"Direito Civil","score":1,"terms":["direito"],"positions":[0],"match":"exact"},{"id":"1","name":"Direito Penal","score":1,"terms":["direito"],"positions":[0],"match":"exact"}],"total":2,"page":1,"per_page":10,"took_ms":0}`},
+		"Search page":            {"GET", "/search?q=dire&page=2&per_page=2", ``, http.StatusOK, `{"hits":[{"id":"3","name":"Direção Defensiva","score":0.5,"terms":["dire"],"positions":[0],"match":"prefix"}],"total":3,"page":2,"per_page":2,"took_ms":0}`},
+		"Search facets":          {"GET", "/search?q=dire&per_page=1&facets=1", ``, http.StatusOK, `{"hits":[{"id":"2","name":"Direito Civil","score":0.5,"terms":["dire"],"positions":[0],"match":"prefix"}],"total":3,"page":1,"per_page":1,"took_ms":0,"facets":{"dire":[{"word":"Direito","count":2}]}}`},
+		"Search nothing found":   {"GET", "/search?q=xyz", ``, http.StatusOK, `{"hits":[],"total":0,"page":1,"per_page":10,"took_ms":0}`},
+		"Search without q":       {"GET", "/search", ``, http.StatusBadRequest, `{"error":"missing the q parameter"}`},
+		"Search invalid page":    {"GET", "/search?q=dire&page=0", ``, http.StatusBadRequest, `{"error":"invalid page parameter: 0"}`},
+		"Search big page size":   {"GET", "/search?q=dire&per_page=1000", ``, http.StatusBadRequest, `{"error":"invalid per_page parameter: 1000"}`},
+		"Autocomplete":           {"GET", "/autocomplete?q=direito%20p&k=2", ``, http.StatusOK, `{"documents":[{"id":"1","name":"Direito Penal"}],"phrases":["direito Penal"]}`},
+		"Autocomplete not found": {"GET", "/autocomplete?q=xyz", ``, http.StatusOK, `{"documents":[],"phrases":[]}`},
+		"Autocomplete invalid k": {"GET", "/autocomplete?q=dir&k=a", ``, http.StatusBadRequest, `{"error":"invalid k parameter: a"}`},
+		"Unknown path":           {"GET", "/words", ``, http.StatusNotFound, `{"error":"not found"}`},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			status, body := request(t, testServer().routes(), tc.method, tc.target, tc.body)
+			if status != tc.status {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.status, status)
+			}
+			diff := cmp.Diff(tc.expected, withoutTook(body))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+// withoutTook replaces the duration of a search response by zero, so the response can be compared
+func withoutTook(body string) string {
+	var response map[string]json.RawMessage
+	if json.Unmarshal([]byte(body), &response) != nil || response["took_ms"] == nil {
+		return body
+	}
+	start := strings.Index(body, `"took_ms":`) + len(`"took_ms":`)
+	end := start + len(response["took_ms"])
+	return body[:start] + "0" + body[end:]
+}
+
+func Test_ChangesAreSearched(t *testing.T) {
+	s := httptest.NewServer(testServer().routes())
+	defer s.Close()
+
+	steps := []struct {
+		method, target, body string
+	}{
+		{"POST", "/documents", `{"id": "5", "name": "Direito-Tributário"}`},
+		{"DELETE", "/documents/1", ``},
+		{"POST", "/documents", `{"id": "2", "name": "Processo Civil"}`},
+	}
+	for _, step := range steps {
+		req, err := http.NewRequest(step.method, s.URL+step.target, strings.NewReader(step.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	resp, err := http.Get(s.URL + "/search?q=direito")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var response searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, h := range response.Hits {
+		ids = append(ids, h.ID)
+	}
+	diff := cmp.Diff([]string{"5"}, ids)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+func Test_ConcurrentRequests(t *testing.T) {
+	handler := testServer().routes()
+	serve := func(method, target, body string) int {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder.Code
+	}
+	codes := make(chan int, 8*20*3)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				codes <- serve("GET", "/search?q=direito%20penal", ``)
+				codes <- serve("GET", "/autocomplete?q=direito%20p", ``)
+				if i == 0 {
+					codes <- serve("POST", "/documents", fmt.Sprintf(`{"id": "%d", "name": "Direito Penal %d"}`, j+10, j))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK && code != http.StatusCreated {
+			t.Fatalf("\nExpected: %v\nGot: %v", http.StatusOK, code)
+		}
+	}
+	code, body := request(t, handler, "GET", "/search?q=direito%20penal&per_page=1", ``)
+	if code != http.StatusOK || !strings.Contains(body, `"total":21`) {
+		t.Fatalf("\nExpected: %v\nGot: %v %v", `"total":21`, code, body)
+	}
+}
+
+func Test_Snapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trie.idx")
+	empty, err := loadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.IsFilled() {
+		t.Fatalf("\nExpected: %v\nGot: %v", false, true)
+	}
+	if err := testServer().save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(testServer().trie.SearchByRelevance("dire"), loaded.SearchByRelevance("dire"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	if err := ioutil.WriteFile(path, []byte("damaged"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSnapshot(path); err != trie.ErrInvalidIndex {
+		t.Fatalf("\nExpected: %v\nGot: %v", trie.ErrInvalidIndex, err)
+	}
+}
+
+func Test_Log(t *testing.T) {
+	dir := t.TempDir()
+	snapshot, logFile := filepath.Join(dir, "trie.idx"), filepath.Join(dir, "trie.log")
+	s, err := openServer(snapshot, logFile, []string{"-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := s.routes()
+	request(t, handler, "POST", "/documents", `{"id": "1", "name": "Direito Penal"}`)
+	request(t, handler, "POST", "/documents", `{"id": "2", "name": "Direito-Tributário"}`)
+	request(t, handler, "DELETE", "/documents/1", ``)
+	expected := trie.NewNode()
+	expected.Add("2", "Direito-Tributário", "-")
+
+	// the server stopped without closing, the changes are replayed from the log
+	s.durable.Close()
+	recovered, err := openServer(snapshot, logFile, []string{"-"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := cmp.Diff(expected.SearchByRelevance("direito"), recovered.trie.SearchByRelevance("direito"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	if err := recovered.close(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadSnapshot(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff = cmp.Diff(expected.SearchByRelevance("direito"), loaded.SearchByRelevance("direito"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+func Test_Metrics(t *testing.T) {
+	handler := testServer().routes()
+	request(t, handler, "POST", "/documents", `{"id": "5", "name": "Direito-Tributário"}`)
+	request(t, handler, "GET", "/search?q=direito", ``)
+	request(t, handler, "DELETE", "/documents/9", ``)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+	// adding a document first removes its ID, so it is also counted as a missing removal
+	for _, line := range []string{"trie_documents 5\n", "trie_adds_total 1\n", "trie_searches_total 1\n", "trie_removes_missing_total 2\n"} {
+		if !strings.Contains(body, line) {
+			t.Fatalf("\nExpected: %v\nGot: %v", line, body)
+		}
+	}
+	if status, _ := request(t, handler, "POST", "/metrics", ``); status != http.StatusMethodNotAllowed {
+		t.Fatalf("\nExpected: %v\nGot: %v", http.StatusMethodNotAllowed, status)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"trie-go/trie"
+)
+
+type document struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// runBuild adds the documents of every input to a trie with a bulk loader and writes it in the index file
+// an input "-" is the standard input, its format must be given by the flag
+func runBuild(c *config, args []string) error {
+	t := trie.NewNode()
+	loader := trie.NewBulkLoader(t, 0, c.remove...)
+	documents := 0
+	for _, input := range args {
+		format := c.format
+		if format == "" {
+			format = strings.TrimPrefix(filepath.Ext(input), ".")
+		}
+		n, err := readInput(input, format, func(doc document) {
+			loader.Add(doc.ID, doc.Name)
+		})
+		if err != nil {
+			loader.Close()
+			return fmt.Errorf("%s: %w", input, err)
+		}
+		documents += n
+	}
+	loader.Close()
+	file, err := os.Create(c.index)
+	if err != nil {
+		return err
+	}
+	if err := t.WriteIndex(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if c.json {
+		return writeJSON(c.out, struct {
+			Documents int    `json:"documents"`
+			Index     string `json:"index"`
+		}{documents, c.index})
+	}
+	_, err = fmt.Fprintf(c.out, "%d documents written to %s\n", documents, c.index)
+	return err
+}
+
+// readInput calls add for every document of the input and returns the number of documents
+func readInput(input, format string, add func(doc document)) (int, error) {
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return 0, err
+		}
+		defer file.Close()
+		r = file
+	}
+	switch format {
+	case "csv":
+		return readCSV(r, add)
+	case "jsonl", "json":
+		return readJSONLines(r, add)
+	}
+	return 0, fmt.Errorf("unknown format %q, use csv or jsonl", format)
+}
+
+// readCSV reads the ID and the name in the first two columns, a first line with the header id,name is skipped
+func readCSV(r io.Reader, add func(doc document)) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	documents := 0
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return documents, nil
+		}
+		if err != nil {
+			return documents, err
+		}
+		if len(record) < 2 {
+			return documents, fmt.Errorf("line %d: expected the id and name columns", line)
+		}
+		if line == 1 && strings.EqualFold(record[0], "id") && strings.EqualFold(record[1], "name") {
+			continue
+		}
+		add(document{ID: record[0], Name: record[1]})
+		documents++
+	}
+}
+
+// readJSONLines reads a JSON object with the id and name in every line, empty lines are skipped
+func readJSONLines(r io.Reader, add func(doc document)) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	documents := 0
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var doc document
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			return documents, fmt.Errorf("line %d: %w", line, err)
+		}
+		if doc.ID == "" {
+			return documents, fmt.Errorf("line %d: the document has no id", line)
+		}
+		add(doc)
+		documents++
+	}
+	return documents, scanner.Err()
+}
@@ -0,0 +1,337 @@
+// Command trie builds index files from CSV or JSON lines and queries them
+//
+//	trie build [-index file] [-format csv|jsonl] [-remove pattern] input...
+//	trie search [-index file] [-page n] [-per-page n] phrase
+//	trie complete [-index file] [-k n] prefix
+//	trie words [-index file] prefix
+//	trie ids [-index file] word
+//	trie stats [-index file]
+//	trie path [-index file] word
+//	trie repl file
+//
+// Every command accepts -json to write JSON instead of a table
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"trie-go/trie"
+)
+
+// errUsage is returned when the arguments of a command are wrong, the usage was already written
+var errUsage = errors.New("invalid arguments")
+
+// command is a subcommand with its flags, run receives the arguments left after the flags
+type command struct {
+	usage string
+	args  int
+	run   func(c *config, args []string) error
+}
+
+// config has the flags shared by the commands and the output
+type config struct {
+	index  string
+	json   bool
+	page   int
+	per    int
+	k      int
+	format string
+	remove removeFlag
+	in     io.Reader
+	out    io.Writer
+}
+
+// removeFlag is a pattern removed from the names of the documents, the flag may be repeated
+type removeFlag []string
+
+func (f *removeFlag) String() string { return strings.Join(*f, " ") }
+func (f *removeFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+var commands = map[string]command{
+	"build":    {"build [-index file] [-format csv|jsonl] [-remove pattern] input...", -1, runBuild},
+	"search":   {"search [-index file] [-page n] [-per-page n] phrase", -1, runSearch},
+	"complete": {"complete [-index file] [-k n] prefix", 1, runComplete},
+	"words":    {"words [-index file] prefix", 1, runWords},
+	"ids":      {"ids [-index file] word", 1, runIDs},
+	"stats":    {"stats [-index file]", 0, runStats},
+	"path":     {"path [-index file] word", 1, runPath},
+	"repl":     {"repl file", 1, runREPL},
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, "trie:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run parses the flags of the command in the arguments and runs it
+func run(args []string, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		writeUsage(stderr)
+		return errUsage
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		writeUsage(stderr)
+		return errUsage
+	}
+	c := &config{in: os.Stdin, out: stdout}
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: trie", cmd.usage)
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&c.index, "index", "trie.idx", "index file")
+	flags.BoolVar(&c.json, "json", false, "write JSON instead of a table")
+	flags.IntVar(&c.page, "page", 1, "page of the results")
+	flags.IntVar(&c.per, "per-page", 10, "results of every page")
+	flags.IntVar(&c.k, "k", 10, "number of completions")
+	flags.StringVar(&c.format, "format", "", "format of the input, csv or jsonl, the extension of the file is used when it is empty")
+	flags.Var(&c.remove, "remove", "pattern removed from the names of the documents, it may be repeated")
+	if err := flags.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+	rest := flags.Args()
+	if (cmd.args >= 0 && len(rest) != cmd.args) || (cmd.args < 0 && len(rest) == 0) {
+		flags.Usage()
+		return errUsage
+	}
+	return cmd.run(c, rest)
+}
+
+func writeUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage:")
+	for _, name := range []string{"build", "search", "complete", "words", "ids", "stats", "path", "repl"} {
+		fmt.Fprintln(w, "  trie", commands[name].usage)
+	}
+}
+
+// open maps the index file to be queried without building the trie, the index must be closed after the command
+func (c *config) open() (*trie.Index, error) {
+	return trie.OpenIndex(c.index)
+}
+
+// load reads the index file as a trie, it is only used by the commands that need the nodes of the trie
+func (c *config) load() (*trie.Node, error) {
+	file, err := os.Open(c.index)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return trie.ReadNode(file)
+}
+
+type searchRow struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+	Match string  `json:"match"`
+}
+
+func runSearch(c *config, args []string) error {
+	index, err := c.open()
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+	pagination := &trie.Pagination{PerPage: int32(c.per), Page: int32(c.page)}
+	result, err := index.SearchContext(context.Background(), strings.Join(args, " "), trie.SearchOptions{Pagination: pagination})
+	if err != nil {
+		return err
+	}
+	rows := make([]searchRow, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		rows = append(rows, searchRow{ID: hit.ID, Name: hit.Name, Score: hit.Score, Match: hit.MatchType.String()})
+	}
+	if c.json {
+		return writeJSON(c.out, struct {
+			Hits  []searchRow `json:"hits"`
+			Total int         `json:"total"`
+		}{rows, result.Total})
+	}
+	table := [][]string{{"ID", "NAME", "SCORE", "MATCH"}}
+	for _, row := range rows {
+		table = append(table, []string{row.ID, row.Name, fmt.Sprintf("%.3f", row.Score), row.Match})
+	}
+	if err := writeTable(c.out, table); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.out, "%d of %d results in %s\n", len(rows), result.Total, result.Took)
+	return err
+}
+
+func runComplete(c *config, args []string) error {
+	index, err := c.open()
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+	words := index.Complete(args[0], c.k)
+	if c.json {
+		return writeJSON(c.out, append([]string{}, words...))
+	}
+	table := [][]string{{"WORD"}}
+	for _, word := range words {
+		table = append(table, []string{word})
+	}
+	return writeTable(c.out, table)
+}
+
+type wordRow struct {
+	Word      string   `json:"word"`
+	Documents []string `json:"documents"`
+}
+
+func runWords(c *config, args []string) error {
+	index, err := c.open()
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+	rows := []wordRow{}
+	index.WalkPrefix(args[0], func(word string, ids []string) bool {
+		rows = append(rows, wordRow{Word: word, Documents: ids})
+		return true
+	})
+	if c.json {
+		return writeJSON(c.out, rows)
+	}
+	table := [][]string{{"WORD", "DOCUMENTS"}}
+	for _, row := range rows {
+		table = append(table, []string{row.Word, fmt.Sprint(len(row.Documents))})
+	}
+	return writeTable(c.out, table)
+}
+
+func runIDs(c *config, args []string) error {
+	index, err := c.open()
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+	ids := index.GetCorrectIDs(args[0])
+	if c.json {
+		return writeJSON(c.out, append([]string{}, ids...))
+	}
+	table := [][]string{{"ID"}}
+	for _, id := range ids {
+		table = append(table, []string{id})
+	}
+	return writeTable(c.out, table)
+}
+
+type stats struct {
+	Documents       int          `json:"documents"`
+	Words           int          `json:"words"`
+	Nodes           int          `json:"nodes"`
+	Postings        int          `json:"postings"`
+	HeapSize        int64        `json:"heap_size"`
+	FileSize        int64        `json:"file_size"`
+	Depths          []int        `json:"depths"`
+	LargestCorrect  []postingRow `json:"largest_correct"`
+	LargestPossible []postingRow `json:"largest_possible"`
+}
+
+type postingRow struct {
+	Prefix string `json:"prefix"`
+	IDs    int    `json:"ids"`
+}
+
+func runStats(c *config, args []string) error {
+	info, err := os.Stat(c.index)
+	if err != nil {
+		return err
+	}
+	t, err := c.load()
+	if err != nil {
+		return err
+	}
+	trieStats := t.Stats()
+	s := stats{
+		Documents:       trieStats.Documents,
+		Words:           trieStats.Words,
+		Nodes:           trieStats.Nodes,
+		Postings:        trieStats.Postings,
+		HeapSize:        trieStats.HeapSize,
+		FileSize:        info.Size(),
+		Depths:          append([]int{}, trieStats.Depths...),
+		LargestCorrect:  []postingRow{},
+		LargestPossible: []postingRow{},
+	}
+	for _, size := range trieStats.LargestCorrect {
+		s.LargestCorrect = append(s.LargestCorrect, postingRow(size))
+	}
+	for _, size := range trieStats.LargestPossible {
+		s.LargestPossible = append(s.LargestPossible, postingRow(size))
+	}
+	if c.json {
+		return writeJSON(c.out, s)
+	}
+	err = writeTable(c.out, [][]string{
+		{"DOCUMENTS", "WORDS", "NODES", "POSTINGS", "HEAP SIZE", "FILE SIZE"},
+		{fmt.Sprint(s.Documents), fmt.Sprint(s.Words), fmt.Sprint(s.Nodes), fmt.Sprint(s.Postings), fmt.Sprint(s.HeapSize), fmt.Sprint(s.FileSize)},
+	})
+	if err != nil {
+		return err
+	}
+	depths := [][]string{{"DEPTH", "NODES"}}
+	for depth, nodes := range s.Depths {
+		depths = append(depths, []string{fmt.Sprint(depth + 1), fmt.Sprint(nodes)})
+	}
+	fmt.Fprintln(c.out)
+	if err := writeTable(c.out, depths); err != nil {
+		return err
+	}
+	largest := [][]string{{"POSTINGS", "PREFIX", "IDS"}}
+	for _, row := range s.LargestCorrect {
+		largest = append(largest, []string{"correct", row.Prefix, fmt.Sprint(row.IDs)})
+	}
+	for _, row := range s.LargestPossible {
+		largest = append(largest, []string{"possible", row.Prefix, fmt.Sprint(row.IDs)})
+	}
+	fmt.Fprintln(c.out)
+	return writeTable(c.out, largest)
+}
+
+type pathRow struct {
+	Prefix        string `json:"prefix"`
+	IsWord        bool   `json:"is_word"`
+	CorrectIDs    int    `json:"correct_ids"`
+	PossibleIDs   int    `json:"possible_ids"`
+	CorrectWords  int    `json:"correct_words"`
+	PossibleWords int    `json:"possible_words"`
+}
+
+// runPath writes the data of every prefix of the word, from its first rune until the word
+func runPath(c *config, args []string) error {
+	t, err := c.load()
+	if err != nil {
+		return err
+	}
+	rows := []pathRow{}
+	for _, info := range t.PathTo(args[0]) {
+		rows = append(rows, pathRow(info))
+	}
+	if c.json {
+		return writeJSON(c.out, rows)
+	}
+	table := [][]string{{"PREFIX", "WORD", "CORRECT IDS", "POSSIBLE IDS", "CORRECT WORDS", "POSSIBLE WORDS"}}
+	for _, row := range rows {
+		table = append(table, []string{row.Prefix, fmt.Sprint(row.IsWord), fmt.Sprint(row.CorrectIDs), fmt.Sprint(row.PossibleIDs), fmt.Sprint(row.CorrectWords), fmt.Sprint(row.PossibleWords)})
+	}
+	return writeTable(c.out, table)
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// buildIndex writes the documents in CSV and JSON lines files and builds the index of both
+func buildIndex(t *testing.T) string {
+	dir := t.TempDir()
+	csvFile := filepath.Join(dir, "docs.csv")
+	jsonFile := filepath.Join(dir, "docs.jsonl")
+	if err := ioutil.WriteFile(csvFile, []byte("id,name\n1,Direito Penal\n2,Direito Civil\n3,\"Direção Defensiva\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(jsonFile, []byte(`{"id": "4", "name": "Administração Pública"}`+"\n\n"+`{"id": "5", "name": "Direito-Administrativo"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	index := filepath.Join(dir, "trie.idx")
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"build", "-index", index, "-remove", "-", csvFile, jsonFile}, &stdout, &stderr); err != nil {
+		t.Fatal(err, stderr.String())
+	}
+	if stdout.String() != "5 documents written to "+index+"\n" {
+		t.Fatalf("\nExpected: %v\nGot: %v", "5 documents written", stdout.String())
+	}
+	return index
+}
+
+var took = regexp.MustCompile(` in .*\n$`)
+
+func Test_Commands(t *testing.T) {
+	index := buildIndex(t)
+
+	cases := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"Search": {[]string{"search", "-index", index, "direito"}, "" +
+			"ID  NAME                    SCORE  MATCH\n" +
+			"2   Direito Civil           1.000  exact\n" +
+			"1   Direito Penal           1.000  exact\n" +
+			"5   Direito-Administrativo  1.000  exact\n" +
+			"3 of 3 results\n"},
+		"Search page": {[]string{"search", "-index", index, "-per-page", "1", "-page", "2", "-json", "admin"}, `{
+  "hits": [
+    {
+      "id": "5",
+      "name": "Direito-Administrativo",
+      "score": 0.25,
+      "match": "prefix"
+    }
+  ],
+  "total": 2
+}
+`},
+		"Complete":      {[]string{"complete", "-index", index, "-k", "2", "d"}, "WORD\nDireito\nDefensiva\n"},
+		"Complete JSON": {[]string{"complete", "-index", index, "-json", "xyz"}, "[]\n"},
+		"Words":         {[]string{"words", "-index", index, "dir"}, "WORD     DOCUMENTS\nDireção  1\nDireito  3\n"},
+		"Words JSON": {[]string{"words", "-index", index, "-json", "pen"}, `[
+  {
+    "word": "Penal",
+    "documents": [
+      "1"
+    ]
+  }
+]
+`},
+		"IDs": {[]string{"ids", "-index", index, "direito"}, "ID\n1\n2\n5\n"},
+		"Path": {[]string{"path", "-index", index, "penal"}, "" +
+			"PREFIX  WORD   CORRECT IDS  POSSIBLE IDS  CORRECT WORDS  POSSIBLE WORDS\n" +
+			"p       false  0            2             0              2\n" +
+			"pe      false  0            1             0              1\n" +
+			"pen     false  0            1             0              1\n" +
+			"pena    false  0            1             0              1\n" +
+			"penal   true   1            0             1              0\n"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if err := run(tc.args, &stdout, &stderr); err != nil {
+				t.Fatal(err, stderr.String())
+			}
+			output := took.ReplaceAllString(stdout.String(), "\n")
+			diff := cmp.Diff(tc.expected, output)
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_Stats(t *testing.T) {
+	index := buildIndex(t)
+	var stdout, stderr bytes.Buffer
+	if err := run([]string{"stats", "-index", index, "-json"}, &stdout, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	var s stats
+	if err := json.Unmarshal(stdout.Bytes(), &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.FileSize <= 0 || s.HeapSize <= 0 {
+		t.Fatalf("\nExpected: %v\nGot: %v and %v", "positive sizes", s.FileSize, s.HeapSize)
+	}
+	expected := stats{
+		Documents: 5,
+		Words:     8,
+		Nodes:     12,
+		Postings:  22,
+		Depths:    []int{4, 6, 2},
+	}
+	diff := cmp.Diff(expected, s, cmpopts.IgnoreFields(stats{}, "HeapSize", "FileSize", "LargestCorrect", "LargestPossible"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	diff = cmp.Diff(postingRow{"direito", 3}, s.LargestCorrect[0])
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	diff = cmp.Diff(postingRow{"d", 4}, s.LargestPossible[0])
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+func Test_Errors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "docs.txt")
+	if err := ioutil.WriteFile(invalid, []byte("1 Direito"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(dir, "docs.jsonl")
+	if err := ioutil.WriteFile(broken, []byte(`{"id": "1"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		args     []string
+		expected string
+	}{
+		"No command":      {nil, errUsage.Error()},
+		"Unknown command": {[]string{"find"}, errUsage.Error()},
+		"Missing word":    {[]string{"ids", "-index", "trie.idx"}, errUsage.Error()},
+		"Unknown flag":    {[]string{"stats", "-size"}, errUsage.Error()},
+		"Unknown format":  {[]string{"build", "-index", filepath.Join(dir, "trie.idx"), invalid}, invalid + `: unknown format "txt", use csv or jsonl`},
+		"Broken JSON":     {[]string{"build", "-index", filepath.Join(dir, "trie.idx"), broken}, broken + ": line 1: unexpected end of JSON input"},
+		"Missing index":   {[]string{"stats", "-index", filepath.Join(dir, "missing.idx")}, "stat " + filepath.Join(dir, "missing.idx") + ": no such file or directory"},
+		"Invalid index":   {[]string{"search", "-index", invalid, "direito"}, "trie: invalid index file"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := run(tc.args, &stdout, &stderr)
+			if err == nil || err.Error() != tc.expected {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.expected, err)
+			}
+		})
+	}
+}
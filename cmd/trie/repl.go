@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+
+	"trie-go/trie"
+)
+
+// Number of words of the trie offered by the tab completion
+const completions = 10
+
+// repl runs the commands typed in a loaded trie and times every one of them
+type repl struct {
+	trie    *trie.Node
+	history []string
+}
+
+// replCommand is a command of the repl, it receives the text typed after its name
+type replCommand struct {
+	usage string
+	run   func(r *repl, w io.Writer, arg string) error
+}
+
+var replCommands map[string]replCommand
+
+func init() {
+	replCommands = map[string]replCommand{
+		"search":   {"search <phrase>        the documents of the phrase with the keys used to rank them", (*repl).search},
+		"explain":  {"explain <id> <phrase>  the terms of the phrase and the keys that rank the document", (*repl).explain},
+		"walk":     {"walk <prefix> [n]      the first n words that start with the prefix and their documents", (*repl).walk},
+		"node":     {"node <word>            the correct and possible data of the node of the word", (*repl).node},
+		"complete": {"complete <prefix>      the words most inserted that start with the prefix", (*repl).complete},
+		"history":  {"history                the commands typed before", (*repl).showHistory},
+		"help":     {"help                   this list", (*repl).help},
+	}
+}
+
+// runREPL loads the index and reads commands until quit or the end of the input
+// when the input is a terminal the lines can be edited, the arrows go through the history and tab completes the words
+func runREPL(c *config, args []string) error {
+	c.index = args[0]
+	t, err := c.load()
+	if err != nil {
+		return err
+	}
+	r := &repl{trie: t}
+	stdin, ok := c.in.(*os.File)
+	if !ok || !term.IsTerminal(int(stdin.Fd())) {
+		return r.run(c.in, c.out)
+	}
+	state, err := term.MakeRaw(int(stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(stdin.Fd()), state)
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{stdin, c.out}, "trie> ")
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		return r.autocomplete(line, pos)
+	}
+	for {
+		line, err := terminal.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if r.execute(line, terminal) {
+			return nil
+		}
+	}
+}
+
+// run executes the lines of the input until it ends or quit is typed
+func (r *repl) run(in io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		if r.execute(scanner.Text(), w) {
+			return nil
+		}
+	}
+	return scanner.Err()
+}
+
+// execute runs the command of the line and writes its time, it returns true when the line asks to quit
+func (r *repl) execute(line string, w io.Writer) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false
+	}
+	name, arg := line, ""
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		name, arg = line[:i], strings.TrimSpace(line[i+1:])
+	}
+	if name == "quit" || name == "exit" {
+		return true
+	}
+	r.history = append(r.history, line)
+	cmd, ok := replCommands[name]
+	if !ok {
+		fmt.Fprintf(w, "unknown command %q, type help for the commands\n", name)
+		return false
+	}
+	start := time.Now()
+	if err := cmd.run(r, w, arg); err != nil {
+		fmt.Fprintln(w, "error:", err)
+		return false
+	}
+	fmt.Fprintf(w, "(%s)\n", time.Since(start))
+	return false
+}
+
+// autocomplete completes the word before the cursor with the command names or the words of the trie
+// the word is extended to the common prefix of the completions, so a single completion is written entirely
+// the words of the trie are completed in the cleaned form it indexes, so a word typed without accents is also completed
+func (r *repl) autocomplete(line string, pos int) (string, int, bool) {
+	start := strings.LastIndexByte(line[:pos], ' ') + 1
+	word := line[start:pos]
+	var candidates []string
+	if start == 0 {
+		for name := range replCommands {
+			if strings.HasPrefix(name, word) {
+				candidates = append(candidates, name)
+			}
+		}
+	} else if word != "" {
+		cleaned := map[string]bool{}
+		for _, candidate := range r.trie.Complete(word, completions) {
+			if candidate = trie.CleanWord(candidate); !cleaned[candidate] {
+				cleaned[candidate] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+		word = trie.CleanWord(word)
+	}
+	completed := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		completed += " "
+	}
+	if len(completed) <= len(word) || !strings.HasPrefix(completed, word) {
+		return "", 0, false
+	}
+	return line[:start] + completed + line[pos:], start + len(completed), true
+}
+
+// commonPrefix returns the longest prefix of all the words, it is shortened by rune so an accented letter is never split
+func commonPrefix(words []string) string {
+	if len(words) == 0 {
+		return ""
+	}
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+func (r *repl) search(w io.Writer, arg string) error {
+	result, err := r.trie.SearchContext(context.Background(), arg, trie.SearchOptions{})
+	if err != nil {
+		return err
+	}
+	table := [][]string{{"RANK", "ID", "NAME", "POSITIONS", "NAME SIZE", "SCORE", "MATCH"}}
+	for i, hit := range result.Hits {
+		table = append(table, []string{fmt.Sprint(i + 1), hit.ID, hit.Name, fmt.Sprint(hit.Positions), fmt.Sprint(len(hit.Name)), fmt.Sprintf("%.3f", hit.Score), hit.MatchType.String()})
+	}
+	if err := writeTable(w, table); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%d results of the terms %v, ranked by the positions, the name size, the name and the ID\n", result.Total, searchTerms(result))
+	return err
+}
+
+func (r *repl) explain(w io.Writer, arg string) error {
+	fields := strings.Fields(arg)
+	if len(fields) < 2 {
+		return fmt.Errorf("usage: %s", replCommands["explain"].usage)
+	}
+	explanation := r.trie.Explain(strings.Join(fields[1:], " "), fields[0])
+	table := [][]string{{"WORD", "NODE", "MATCH", "DOCUMENTS", "POSITIONS"}}
+	for _, term := range explanation.Terms {
+		positions := "not matched"
+		if term.Matched {
+			positions = fmt.Sprint(term.Positions)
+		}
+		table = append(table, []string{term.Word, term.Node, term.MatchType.String(), fmt.Sprint(term.Documents), positions})
+	}
+	if err := writeTable(w, table); err != nil {
+		return err
+	}
+	if !explanation.Found {
+		_, err := fmt.Fprintf(w, "%s is not in the %d results\n", explanation.ID, explanation.Total)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%s %s is %d of %d, positions %v, name size %d, score %.3f, %s\n", explanation.ID, explanation.Name,
+		explanation.Rank, explanation.Total, explanation.Positions, explanation.NameSize, explanation.Score, explanation.MatchType)
+	return err
+}
+
+func searchTerms(result trie.SearchResult) []string {
+	if len(result.Hits) == 0 {
+		return nil
+	}
+	return result.Hits[0].Terms
+}
+
+func (r *repl) walk(w io.Writer, arg string) error {
+	fields := strings.Fields(arg)
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("usage: %s", replCommands["walk"].usage)
+	}
+	limit := completions
+	if len(fields) == 2 {
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid number of words %q", fields[1])
+		}
+		limit = n
+	}
+	table := [][]string{{"WORD", "IDS"}}
+	r.trie.WalkPrefix(fields[0], func(word string, ids []string) bool {
+		table = append(table, []string{word, strings.Join(ids, " ")})
+		return len(table) <= limit
+	})
+	return writeTable(w, table)
+}
+
+func (r *repl) node(w io.Writer, arg string) error {
+	info, ok := r.trie.NodeInfo(arg)
+	if !ok {
+		return fmt.Errorf("%q is not in the trie", arg)
+	}
+	if _, err := fmt.Fprintf(w, "prefix %s, word %t\n", info.Prefix, info.IsWord); err != nil {
+		return err
+	}
+	return writeTable(w, [][]string{
+		{"", "IDS", "WORDS"},
+		{"correct", strings.Join(r.trie.GetCorrectIDs(arg), " "), strings.Join(r.trie.GetCorrectWords(arg), " ")},
+		{"possible", strings.Join(r.trie.GetPossibleIDs(arg), " "), strings.Join(r.trie.GetPossibleWords(arg), " ")},
+	})
+}
+
+func (r *repl) complete(w io.Writer, arg string) error {
+	_, err := fmt.Fprintln(w, strings.Join(r.trie.Complete(arg, completions), " "))
+	return err
+}
+
+func (r *repl) showHistory(w io.Writer, arg string) error {
+	for i, line := range r.history {
+		fmt.Fprintf(w, "%4d  %s\n", i+1, line)
+	}
+	return nil
+}
+
+func (r *repl) help(w io.Writer, arg string) error {
+	names := make([]string, 0, len(replCommands))
+	for name := range replCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(w, " ", replCommands[name].usage)
+	}
+	_, err := fmt.Fprintln(w, "  quit                   leave the repl")
+	return err
+}
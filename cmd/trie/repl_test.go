@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var elapsed = regexp.MustCompile(`(?m)^\(.*\)\n`)
+
+func Test_REPL(t *testing.T) {
+	index := buildIndex(t)
+	var stdout bytes.Buffer
+	c := &config{in: strings.NewReader("search penal\n\nwalk dir 1\nnode direito\nnode xyz\nexplain 5 direito admin\nexplain 4 penal\nfind\nhistory\nquit\nsearch civil\n"), out: &stdout}
+	if err := runREPL(c, []string{index}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "" +
+		"RANK  ID  NAME           POSITIONS  NAME SIZE  SCORE  MATCH\n" +
+		"1     1   Direito Penal  [1]        13         0.500  exact\n" +
+		"1 results of the terms [penal], ranked by the positions, the name size, the name and the ID\n" +
+		"WORD     IDS\n" +
+		"Direção  3\n" +
+		"prefix direito, word true\n" +
+		"          IDS    WORDS\n" +
+		"correct   1 2 5  Direito\n" +
+		"possible         \n" +
+		"error: \"xyz\" is not in the trie\n" +
+		"WORD     NODE     MATCH   DOCUMENTS  POSITIONS\n" +
+		"direito  direito  exact   3          [0]\n" +
+		"admin    admin    prefix  2          [1]\n" +
+		"5 Direito-Administrativo is 1 of 1, positions [0 1], name size 22, score 0.750, prefix\n" +
+		"WORD   NODE   MATCH  DOCUMENTS  POSITIONS\n" +
+		"penal  penal  exact  1          not matched\n" +
+		"4 is not in the 1 results\n" +
+		"unknown command \"find\", type help for the commands\n" +
+		"   1  search penal\n" +
+		"   2  walk dir 1\n" +
+		"   3  node direito\n" +
+		"   4  node xyz\n" +
+		"   5  explain 5 direito admin\n" +
+		"   6  explain 4 penal\n" +
+		"   7  find\n" +
+		"   8  history\n"
+	diff := cmp.Diff(expected, elapsed.ReplaceAllString(stdout.String(), ""))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+func Test_CommonPrefix(t *testing.T) {
+	cases := map[string]struct {
+		words    []string
+		expected string
+	}{
+		"No words":             {nil, ""},
+		"One word":             {[]string{"direito"}, "direito"},
+		"Same prefix":          {[]string{"direito", "direção", "dir"}, "dir"},
+		"Accents after prefix": {[]string{"direção", "direções"}, "direç"},
+		"Nothing in common":    {[]string{"penal", "civil"}, ""},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if got := commonPrefix(tc.words); got != tc.expected {
+				t.Fatalf("\nExpected: %q\nGot: %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func Test_Autocomplete(t *testing.T) {
+	index := buildIndex(t)
+	c := &config{index: index}
+	tr, err := c.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &repl{trie: tr}
+
+	cases := map[string]struct {
+		line     string
+		pos      int
+		expected string
+		ok       bool
+	}{
+		"Command":         {"se", 2, "search ", true},
+		"Many commands":   {"h", 1, "h", false},
+		"Word":            {"search pen", 10, "search penal ", true},
+		"Common prefix":   {"walk di", 7, "walk dire", true},
+		"Before the rest": {"search adm civil", 10, "search administra civil", true},
+		"Without accents": {"search administrac", 18, "search administracao ", true},
+		"With accents":    {"search direç", 13, "search direcao ", true},
+		"Unknown word":    {"search xyz", 10, "search xyz", false},
+		"Empty word":      {"search ", 7, "search ", false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			line, _, ok := r.autocomplete(tc.line, tc.pos)
+			if !ok {
+				line = tc.line
+			}
+			if line != tc.expected || ok != tc.ok {
+				t.Fatalf("\nExpected: %v %v\nGot: %v %v", tc.expected, tc.ok, line, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package trie
+
+import "container/heap"
+
+// Complete return the k words most inserted that start with the prefix, the prefix itself is included if it is a word
+// Up to maxCompletions words are read from the list kept in the node, so the size of the trie does not matter
+// bigger requests select the words of the node with a bounded heap
+func (t *Node) Complete(prefix string, k int) []string {
+	if k <= 0 {
+		return nil
+	}
+	node := t.getNode(cleanString(prefix))
+	if node == nil {
+		return nil
+	}
+	if k <= maxCompletions {
+		if k > len(node.topWords) {
+			k = len(node.topWords)
+		}
+		var words []string
+		for _, top := range node.topWords[:k] {
+			words = append(words, top.word)
+		}
+		return words
+	}
+	return selectTopWords(k, node.correctWords, node.possibleWords)
+}
+
+// updateTopWords keeps the best completions of the node sorted after the count of the word is increased
+// as the counts only increase, a word that is not in the list can only enter it by taking the place of the last one
+func (t *Node) updateTopWords(word string, count int) {
+	entry := wordCount{word: word, count: count}
+	i := 0
+	for i < len(t.topWords) && t.topWords[i].word != word {
+		i++
+	}
+	switch {
+	case i < len(t.topWords):
+		t.topWords[i] = entry
+	case len(t.topWords) < maxCompletions:
+		t.topWords = append(t.topWords, entry)
+	case entry.before(t.topWords[i-1]):
+		i--
+		t.topWords[i] = entry
+	default:
+		return
+	}
+	for i > 0 && t.topWords[i].before(t.topWords[i-1]) {
+		t.topWords[i], t.topWords[i-1] = t.topWords[i-1], t.topWords[i]
+		i--
+	}
+}
+
+// selectTopWords returns the k best words of the maps keeping at most k words in memory
+func selectTopWords(k int, wordMaps ...map[string]int) []string {
+	h := &wordHeap{}
+	for _, wordMap := range wordMaps {
+		for word, count := range wordMap {
+			entry := wordCount{word: word, count: count}
+			if h.Len() < k {
+				heap.Push(h, entry)
+			} else if entry.before((*h)[0]) {
+				(*h)[0] = entry
+				heap.Fix(h, 0)
+			}
+		}
+	}
+	words := make([]string, h.Len())
+	for i := len(words) - 1; i >= 0; i-- {
+		words[i] = heap.Pop(h).(wordCount).word
+	}
+	if len(words) == 0 {
+		return nil
+	}
+	return words
+}
+
+// mostInserted returns the word inserted more times, it is empty if there is none
+func mostInserted(words map[string]int) string {
+	top := selectTopWords(1, words)
+	if len(top) == 0 {
+		return ""
+	}
+	return top[0]
+}
+
+// wordHeap keeps the worst completion in the top, so it is the one replaced
+type wordHeap []wordCount
+
+func (h wordHeap) Len() int            { return len(h) }
+func (h wordHeap) Less(i, j int) bool  { return h[j].before(h[i]) }
+func (h wordHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *wordHeap) Push(x interface{}) { *h = append(*h, x.(wordCount)) }
+func (h *wordHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
@@ -0,0 +1,124 @@
+package trie
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Complete(t *testing.T) {
+	trieNode := NewNode()
+	trieNode.Add("1", "Direito Penal")
+	trieNode.Add("2", "Direito Penal Militar")
+	trieNode.Add("3", "Direitos Humanos")
+	trieNode.Add("4", "Direção Defensiva")
+	trieNode.Add("5", "direito")
+
+	cases := map[string]struct {
+		prefix   string
+		k        int
+		expected []string
+	}{
+		"Most inserted first":      {"dire", 10, []string{"Direito", "Direitos", "Direção", "direito"}},
+		"Limited by k":             {"dire", 2, []string{"Direito", "Direitos"}},
+		"Prefix is a word":         {"direito", 10, []string{"Direito", "Direitos", "direito"}},
+		"Prefix with accents":      {"direcao", 10, []string{"Direção"}},
+		"Prefix not found":         {"penalidade", 10, nil},
+		"Zero completions":         {"dire", 0, nil},
+		"More than the node keeps": {"dire", 20, []string{"Direito", "Direitos", "Direção", "direito"}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expected, trieNode.Complete(tc.prefix, tc.k))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_CompleteMoreThanMaxCompletions(t *testing.T) {
+	trieNode := NewNode()
+	var expected []string
+	for i := 0; i < 2*maxCompletions; i++ {
+		word := fmt.Sprintf("palavra%02d", i)
+		for j := 0; j <= i; j++ {
+			trieNode.Add(strconv.Itoa(j), word)
+		}
+		expected = append([]string{word}, expected...)
+	}
+
+	diff := cmp.Diff(expected[:maxCompletions], trieNode.Complete("pal", maxCompletions))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	diff = cmp.Diff(expected[:maxCompletions+5], trieNode.Complete("pal", maxCompletions+5))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+var syllables = []string{"ba", "be", "ca", "ci", "da", "de", "fa", "fo", "ga", "gu", "la", "li", "ma", "mo", "na", "ne", "pa", "pe", "ra", "ri", "sa", "so", "ta", "te", "va", "vi"}
+
+// syntheticTries keeps the tries already built, so every benchmark of the same size uses the same trie
+var syntheticTries = make(map[int]*Node)
+
+// syntheticTrie returns a trie with the number of words, they are grouped in names with four words
+func syntheticTrie(words int) *Node {
+	if trieNode, ok := syntheticTries[words]; ok {
+		return trieNode
+	}
+	trieNode := NewNode()
+	for _, doc := range syntheticDocuments(words) {
+		trieNode.Add(doc.ID, doc.Name)
+	}
+	syntheticTries[words] = trieNode
+	return trieNode
+}
+
+// syntheticDocuments returns the documents of syntheticTrie, the names have four words made of random syllables
+func syntheticDocuments(words int) []Document {
+	rng := rand.New(rand.NewSource(int64(words)))
+	docs := make([]Document, 0, words/4)
+	for i := 0; i < words/4; i++ {
+		name := make([]string, 4)
+		for j := range name {
+			var word strings.Builder
+			for s := 0; s < 2+rng.Intn(3); s++ {
+				word.WriteString(syllables[rng.Intn(len(syllables))])
+			}
+			name[j] = word.String()
+		}
+		docs = append(docs, Document{ID: strconv.Itoa(i), Name: strings.Join(name, " ")})
+	}
+	return docs
+}
+
+func Benchmark_Complete(b *testing.B) {
+	for _, words := range []int{10000, 100000, 1000000} {
+		b.Run(fmt.Sprintf("words=%d", words), func(b *testing.B) {
+			trieNode := syntheticTrie(words)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				trieNode.Complete(syllables[i%len(syllables)], maxCompletions)
+			}
+		})
+	}
+}
+
+func Benchmark_GetPossibleWords(b *testing.B) {
+	for _, words := range []int{10000, 100000, 1000000} {
+		b.Run(fmt.Sprintf("words=%d", words), func(b *testing.B) {
+			trieNode := syntheticTrie(words)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				trieNode.GetPossibleWords(syllables[i%len(syllables)])
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package trie
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrCanceled is returned when the context of a search is done before the search ends
+	// the error returned also wraps the error of the context, so it can be compared with context.DeadlineExceeded
+	ErrCanceled = errors.New("trie: search canceled")
+	// ErrTooManyCandidates is returned when a term of the search has more documents than the MaxCandidates of the options
+	ErrTooManyCandidates = errors.New("trie: too many candidates")
+)
+
+// canceledError is ErrCanceled with the error of the context
+type canceledError struct {
+	err error
+}
+
+func (e canceledError) Error() string        { return ErrCanceled.Error() + ": " + e.err.Error() }
+func (e canceledError) Is(target error) bool { return target == ErrCanceled }
+func (e canceledError) Unwrap() error        { return e.err }
+
+// candidatesError is ErrTooManyCandidates with the number of candidates of the search
+type candidatesError struct {
+	candidates int
+}
+
+func (e candidatesError) Error() string        { return ErrTooManyCandidates.Error() }
+func (e candidatesError) Is(target error) bool { return target == ErrTooManyCandidates }
+
+// checkContext returns ErrCanceled if the context is done
+func checkContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return canceledError{err: ctx.Err()}
+	default:
+		return nil
+	}
+}
+
+// SearchContext return the same hits of SearchByRelevance, the context is checked between the words of the phrase and the intersections
+// The postings are only intersected if the term with the fewest documents has no more than the MaxCandidates of the options
+func (t *Node) SearchContext(ctx context.Context, phrase string, opts SearchOptions) (SearchResult, error) {
+	start := time.Now()
+	terms, p, err := t.searchTerms(ctx, phrase, opts.MaxCandidates, nil)
+	result := SearchResult{}
+	if err == nil {
+		result, err = searchResult(ctx, start, t.documents, terms, p, opts)
+	}
+	if t.observer != nil {
+		observeSearch(t.observer, start, p, result, len(t.documents.ordinals), err)
+	}
+	return result, err
+}
+
+// SearchContext return the same hits of SearchByRelevance, the context is also checked while the records of a prefix are read
+func (idx *Index) SearchContext(ctx context.Context, phrase string, opts SearchOptions) (SearchResult, error) {
+	start := time.Now()
+	terms, p, err := idx.searchTerms(ctx, phrase, opts.MaxCandidates, nil)
+	result := SearchResult{}
+	if err == nil {
+		result, err = searchResult(ctx, start, idx.documents, terms, p, opts)
+	}
+	if idx.observer != nil {
+		observeSearch(idx.observer, start, p, result, idx.documents.size, err)
+	}
+	return result, err
+}
+
+// intersectCandidates intersects the postings when the postings with the fewest documents have no more than the maximum
+// no document is found out of the smallest postings, so they bound the documents intersected and ordered, zero has no maximum
+func intersectCandidates(ctx context.Context, postingList []*postings, maxCandidates int) (*postings, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	if size := candidates(postingList); maxCandidates > 0 && size > maxCandidates {
+		return nil, candidatesError{candidates: size}
+	}
+	return intersectListContext(ctx, postingList)
+}
+
+// candidates returns the number of documents of the smallest postings
+func candidates(postingList []*postings) int {
+	sizes := make([]int, len(postingList))
+	for i, p := range postingList {
+		sizes[i] = -1
+		if p != nil {
+			sizes[i] = p.len()
+		}
+	}
+	return fewest(sizes)
+}
+
+// fewest returns the smallest of the sizes of the postings, a negative size is a nil postings
+// a nil postings after the first one has no documents and a nil postings in the beginning is ignored, in the same way they are intersected
+func fewest(sizes []int) int {
+	smallest := -1
+	for _, size := range sizes {
+		switch {
+		case size < 0 && smallest < 0:
+			continue
+		case size < 0:
+			return 0
+		case smallest < 0 || size < smallest:
+			smallest = size
+		}
+	}
+	if smallest < 0 {
+		return 0
+	}
+	return smallest
+}
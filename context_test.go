@@ -0,0 +1,95 @@
+package trie
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func hitsOf(data []SearchData) []Hit {
+	var hits []Hit
+	for _, value := range data {
+		hits = append(hits, Hit{ID: value.ID, Name: value.Name})
+	}
+	return hits
+}
+
+func Test_SearchContext(t *testing.T) {
+	cases := map[string]struct {
+		phrase   string
+		opts     SearchOptions
+		expected SearchResult
+	}{
+		"Every hit":           {"direito", SearchOptions{}, SearchResult{Hits: hitsOf(indexTrie().SearchByRelevance("direito")), Total: 4}},
+		"Limit":               {"dire", SearchOptions{Limit: 2}, SearchResult{Hits: hitsOf(indexTrie().SearchByRelevance("dire")[:2]), Total: 5}},
+		"Limit bigger":        {"penal", SearchOptions{Limit: 10}, SearchResult{Hits: hitsOf(indexTrie().SearchByRelevance("penal")), Total: 2}},
+		"Maximum candidates":  {"direito penal", SearchOptions{MaxCandidates: 2}, SearchResult{Hits: hitsOf(indexTrie().SearchByRelevance("direito penal")), Total: 2}},
+		"Nothing found":       {"xyz", SearchOptions{}, SearchResult{}},
+		"Only short words":    {"di", SearchOptions{}, SearchResult{}},
+		"Limit and candidate": {"dire", SearchOptions{Limit: 1, MaxCandidates: 5}, SearchResult{Hits: hitsOf(indexTrie().SearchByRelevance("dire")[:1]), Total: 5}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			result, err := indexTrie().SearchContext(context.Background(), tc.phrase, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff := cmp.Diff(tc.expected, result, cmpopts.IgnoreFields(SearchResult{}, "Took"), cmpopts.IgnoreFields(Hit{}, "Score", "Terms", "Positions", "MatchType"))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_SearchContextErrors(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	cases := map[string]struct {
+		ctx      context.Context
+		phrase   string
+		opts     SearchOptions
+		expected []error
+	}{
+		"Canceled":             {canceled, "direito", SearchOptions{}, []error{ErrCanceled, context.Canceled}},
+		"Deadline exceeded":    {expired, "direito", SearchOptions{}, []error{ErrCanceled, context.DeadlineExceeded}},
+		"Too many candidates":  {context.Background(), "direito", SearchOptions{MaxCandidates: 3}, []error{ErrTooManyCandidates}},
+		"Maximum and canceled": {canceled, "direito", SearchOptions{MaxCandidates: 3}, []error{ErrCanceled}},
+		"Every term too big":   {context.Background(), "direito penal", SearchOptions{MaxCandidates: 1}, []error{ErrTooManyCandidates}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, searcher := range []SearcherInterface{indexTrie(), loadedIndex(t), shardedIndex(3)} {
+				result, err := searcher.SearchContext(tc.ctx, tc.phrase, tc.opts)
+				for _, expected := range tc.expected {
+					if !errors.Is(err, expected) {
+						t.Fatalf("\nExpected: %v\nGot: %v", expected, err)
+					}
+				}
+				if result.Hits != nil || result.Total != 0 {
+					t.Fatalf("\nExpected: %v\nGot: %v", SearchResult{}, result)
+				}
+			}
+		})
+	}
+}
+
+func loadedIndex(t *testing.T) *Index {
+	index, err := OpenIndex(writeIndexFile(t, indexTrie()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		index.Close()
+	})
+	return index
+}
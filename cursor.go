@@ -0,0 +1,72 @@
+package trie
+
+import (
+	"encoding/base64"
+	"sort"
+)
+
+// Version of the cursor format, a cursor of another version is invalid
+const cursorVersion = 1
+
+// paginateValues orders the values and returns the items of the page, in the cursor mode the items after the cursor are returned
+// an invalid cursor returns no items, as it is not known where the last page stopped
+// the sort key of a document only changes when it is added again, the searches do not change the positions in the nodes,
+// so the items after the cursor are the same when other phrases are searched between the pages
+func paginateValues(values byRelevance, pagination Pagination) (byRelevance, Pagination) {
+	sort.Sort(values)
+	if pagination.Mode != CursorMode {
+		return paginateList(values, pagination)
+	}
+	pagination.NextCursor = ""
+	start := 0
+	if pagination.Cursor != "" {
+		last, ok := decodeCursor(pagination.Cursor)
+		if !ok {
+			return nil, pagination
+		}
+		start = sort.Search(len(values), func(i int) bool {
+			return last.before(values[i])
+		})
+	}
+	end := len(values)
+	if pagination.PerPage >= 0 && start+int(pagination.PerPage) < end {
+		end = start + int(pagination.PerPage)
+	}
+	if end < len(values) && end > start {
+		pagination.NextCursor = encodeCursor(values[end-1])
+	}
+	pagination.Total = int32(len(values))
+	return values[start:end], pagination
+}
+
+// encodeCursor returns the sort key and the ID of the data in an opaque string that is safe in URLs
+func encodeCursor(data *internalOrderData) string {
+	cursor := []byte{cursorVersion}
+	cursor = appendUvarint(cursor, len(data.position))
+	for _, position := range data.position {
+		cursor = appendUvarint(cursor, position)
+	}
+	cursor = append(appendUvarint(cursor, len(data.name)), data.name...)
+	cursor = append(cursor, data.id...)
+	return base64.RawURLEncoding.EncodeToString(cursor)
+}
+
+// decodeCursor returns the data encoded in the cursor, it is false if the cursor is not valid
+func decodeCursor(cursor string) (*internalOrderData, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) == 0 || raw[0] != cursorVersion {
+		return nil, false
+	}
+	d := &decoder{data: raw[1:]}
+	data := &internalOrderData{}
+	size := d.uvarint()
+	if size > len(d.data) {
+		return nil, false
+	}
+	for i := 0; i < size; i++ {
+		data.position = append(data.position, d.uvarint())
+	}
+	data.name = string(d.bytes(d.uvarint()))
+	data.id = string(d.data)
+	return data, !d.failed
+}
@@ -0,0 +1,143 @@
+package trie
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// cursorDocs are the documents of cursorTrie, two of them have the same name
+var cursorDocs = []Document{
+	{"1", "Direito Penal"}, {"2", "Direito Civil"}, {"3", "Direito Penal Militar"}, {"4", "Direito Civil"},
+	{"5", "Direito Administrativo"}, {"6", "Direito Tributário"},
+}
+
+func cursorTrie() *Node {
+	return addDocuments(cursorDocs)
+}
+
+func readAllPages(s SearcherInterface, phrase string, perPage int32) ([]SearchData, int) {
+	var results []SearchData
+	pagination := Pagination{PerPage: perPage, Mode: CursorMode}
+	pages := 0
+	for {
+		data, next := s.SearchByRelevancePaginated(phrase, pagination)
+		results = append(results, data...)
+		pages++
+		if next.NextCursor == "" {
+			return results, pages
+		}
+		pagination.Cursor = next.NextCursor
+	}
+}
+
+func Test_CursorPagination(t *testing.T) {
+	cases := map[string]struct {
+		phrase        string
+		perPage       int32
+		expectedPages int
+	}{
+		"Pages of two":          {"direito", 2, 3},
+		"Last page incomplete":  {"direito", 4, 2},
+		"Only one page":         {"direito", 10, 1},
+		"Same name and words":   {"civil", 1, 2},
+		"Nothing found":         {"xyz", 2, 1},
+		"Page with the results": {"direito", 6, 1},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			results, pages := readAllPages(cursorTrie(), tc.phrase, tc.perPage)
+			diff := cmp.Diff(cursorTrie().SearchByRelevance(tc.phrase), results)
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			if pages != tc.expectedPages {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.expectedPages, pages)
+			}
+		})
+	}
+}
+
+func Test_CursorAfterAddingData(t *testing.T) {
+	trieNode := cursorTrie()
+	first, pagination := trieNode.SearchByRelevancePaginated("direito", Pagination{PerPage: 3, Mode: CursorMode})
+	trieNode.Add("7", "Direito")
+	trieNode.Add("8", "Direito Zzzzzzz")
+	second, pagination := trieNode.SearchByRelevancePaginated("direito", Pagination{PerPage: 3, Mode: CursorMode, Cursor: pagination.NextCursor})
+
+	diff := cmp.Diff([]SearchData{{"2", "Direito Civil"}, {"4", "Direito Civil"}, {"1", "Direito Penal"}}, first)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	diff = cmp.Diff([]SearchData{{"8", "Direito Zzzzzzz"}, {"6", "Direito Tributário"}, {"3", "Direito Penal Militar"}}, second)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	if pagination.Total != 8 {
+		t.Fatalf("\nExpected: %v\nGot: %v", 8, pagination.Total)
+	}
+}
+
+func Test_CursorAfterOtherSearches(t *testing.T) {
+	sharded := NewShardedIndex(3)
+	for _, doc := range cursorDocs {
+		sharded.Add(doc.ID, doc.Name)
+	}
+	cases := map[string]struct {
+		searcher SearcherInterface
+	}{
+		"Trie":          {cursorTrie()},
+		"Sharded index": {sharded},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var results []SearchData
+			seen := make(map[string]bool)
+			pagination := Pagination{PerPage: 1, Mode: CursorMode}
+			for {
+				data, next := tc.searcher.SearchByRelevancePaginated("direito", pagination)
+				for _, d := range data {
+					if seen[d.ID] {
+						t.Fatalf("\nExpected: %v\nGot: %v", "no repeated IDs", d.ID)
+					}
+					seen[d.ID] = true
+				}
+				results = append(results, data...)
+				if next.NextCursor == "" {
+					break
+				}
+				// searches of other phrases between the pages do not move the items of the next page
+				tc.searcher.SearchByRelevance("direito penal")
+				tc.searcher.SearchByRelevancePaginated("penal direito militar", Pagination{PerPage: 2, Page: 1})
+				pagination.Cursor = next.NextCursor
+			}
+			diff := cmp.Diff(cursorTrie().SearchByRelevance("direito"), results)
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_InvalidCursor(t *testing.T) {
+	cases := map[string]struct {
+		cursor string
+	}{
+		"Not base64":        {"!!!"},
+		"Other version":     {base64.RawURLEncoding.EncodeToString([]byte{2, 0, 0})},
+		"Positions too big": {base64.RawURLEncoding.EncodeToString([]byte{cursorVersion, 100, 1})},
+		"Truncated name":    {base64.RawURLEncoding.EncodeToString([]byte{cursorVersion, 0, 10, 'a'})},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			data, pagination := cursorTrie().SearchByRelevancePaginated("direito", Pagination{PerPage: 2, Mode: CursorMode, Cursor: tc.cursor})
+			if data != nil || pagination.NextCursor != "" {
+				t.Fatalf("\nExpected: %v\nGot: %v %v", nil, data, pagination.NextCursor)
+			}
+		})
+	}
+}
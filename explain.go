@@ -0,0 +1,88 @@
+package trie
+
+import "context"
+
+// termVisitor receives a word of a phrase, the prefix it was resolved to and the postings used by the search
+type termVisitor func(word, prefix string, exact bool, p *postings)
+
+// Explain return the terms of the phrase and the keys that rank the document of the ID
+// the positions of every term are the ones of its node, read before the postings are intersected
+func (t *Node) Explain(phrase, id string) Explanation {
+	doc, known := t.documents.ordinals[id]
+	return explain(t.documents, id, doc, known, func(visit termVisitor) ([]queryTerm, *postings) {
+		terms, p, _ := t.searchTerms(context.Background(), phrase, 0, visit)
+		return terms, p
+	})
+}
+
+// Explain return the terms of the phrase and the keys that rank the document of the ID
+// the document is found by reading every ID of the file
+func (idx *Index) Explain(phrase, id string) Explanation {
+	doc, known := idx.documents.ordinal(id)
+	return explain(idx.documents, id, doc, known, func(visit termVisitor) ([]queryTerm, *postings) {
+		terms, p, _ := idx.searchTerms(context.Background(), phrase, 0, visit)
+		return terms, p
+	})
+}
+
+// explain runs the search and explains every term and the rank of the document in the result
+func explain(source documentSource, id string, doc uint32, known bool, search func(visit termVisitor) ([]queryTerm, *postings)) Explanation {
+	explanation := Explanation{ID: id}
+	if known {
+		_, explanation.Name = source.document(doc)
+	}
+	terms, p := search(func(word, prefix string, exact bool, p *postings) {
+		term := TermExplanation{Word: word, Node: prefix, MatchType: MatchPrefix}
+		if exact {
+			term.MatchType = MatchExact
+		}
+		if p != nil {
+			term.Documents = p.len()
+			if known && p.docs.contains(doc) {
+				term.Matched = true
+				term.Positions = append([]int{}, p.positions[p.docs.rank(doc)-1]...)
+			}
+		}
+		explanation.Terms = append(explanation.Terms, term)
+	})
+	if p == nil {
+		return explanation
+	}
+	explanation.Total = p.len()
+	if !known || !p.docs.contains(doc) {
+		return explanation
+	}
+	values := relevanceValues(source, p)
+	value := values[p.docs.rank(doc)-1]
+	explanation.Found = true
+	explanation.Rank = 1
+	for _, other := range values {
+		if other.before(value) {
+			explanation.Rank++
+		}
+	}
+	hit := newHits(byRelevance{value}, terms)[0]
+	explanation.Positions = hit.Positions
+	explanation.NameSize = len(value.name)
+	explanation.Score = hit.Score
+	explanation.MatchType = hit.MatchType
+	return explanation
+}
+
+// DecidingKey return the first key that orders the documents of both explanations, the positions, the name size, the name or the ID
+// it is empty when a document was not found or both are the same
+func (e Explanation) DecidingKey(other Explanation) string {
+	switch {
+	case !e.Found || !other.Found:
+		return ""
+	case dist(e.Positions, other.Positions) != 0:
+		return "positions"
+	case e.NameSize != other.NameSize:
+		return "name size"
+	case e.Name != other.Name:
+		return "name"
+	case e.ID != other.ID:
+		return "id"
+	}
+	return ""
+}
@@ -0,0 +1,99 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Explain(t *testing.T) {
+	cases := map[string]struct {
+		phrase   string
+		id       string
+		expected Explanation
+	}{
+		"Phrase": {"direito penal", "7", Explanation{
+			ID: "7", Name: "Direito Penal Militar", Found: true, Rank: 2, Total: 2,
+			Terms: []TermExplanation{
+				{Word: "direito", Node: "direito", MatchType: MatchExact, Documents: 4, Matched: true, Positions: []int{0}},
+				{Word: "penal", Node: "penal", MatchType: MatchExact, Documents: 2, Matched: true, Positions: []int{1}},
+			},
+			Positions: []int{0, 1}, NameSize: 21, Score: 1, MatchType: MatchExact,
+		}},
+		"Prefix": {"dire", "3", Explanation{
+			ID: "3", Name: "Direção Defensiva", Found: true, Rank: 3, Total: 5,
+			Terms: []TermExplanation{
+				{Word: "dire", Node: "dire", MatchType: MatchPrefix, Documents: 5, Matched: true, Positions: []int{0}},
+			},
+			Positions: []int{0}, NameSize: 19, Score: 0.5, MatchType: MatchPrefix,
+		}},
+		"Longest prefix": {"direitos", "2", Explanation{
+			ID: "2", Name: "Direito Civil", Found: true, Rank: 1, Total: 4,
+			Terms: []TermExplanation{
+				{Word: "direitos", Node: "direito", MatchType: MatchExact, Documents: 4, Matched: true, Positions: []int{0}},
+			},
+			Positions: []int{0}, NameSize: 13, Score: 1, MatchType: MatchExact,
+		}},
+		"Document without a term": {"dir admin", "5", Explanation{
+			ID: "5", Name: "Direito Administrativo",
+			Terms: []TermExplanation{
+				{Word: "dir", Node: "dir", MatchType: MatchExact, Documents: 1},
+				{Word: "admin", Node: "admin", MatchType: MatchPrefix, Documents: 2, Matched: true, Positions: []int{1}},
+			},
+		}},
+		"Unknown document": {"penal", "9", Explanation{
+			ID: "9", Total: 2,
+			Terms: []TermExplanation{
+				{Word: "penal", Node: "penal", MatchType: MatchExact, Documents: 2},
+			},
+		}},
+		"Short words": {"de", "3", Explanation{ID: "3", Name: "Direção Defensiva"}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expected, indexTrie().Explain(tc.phrase, tc.id))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_ExplainRemoved(t *testing.T) {
+	trieNode := indexTrie()
+	trieNode.Remove("1")
+	explanation := trieNode.Explain("direito penal", "7")
+	if !explanation.Found || explanation.Rank != 1 || explanation.Total != 1 {
+		t.Fatalf("\nExpected: %v\nGot: %v", "rank 1 of 1", explanation)
+	}
+	if explanation := trieNode.Explain("direito penal", "1"); explanation.Found || explanation.Name != "" {
+		t.Fatalf("\nExpected: %v\nGot: %v", "not found", explanation)
+	}
+}
+
+func Test_DecidingKey(t *testing.T) {
+	cases := map[string]struct {
+		phrase   string
+		first    string
+		second   string
+		expected string
+	}{
+		"Positions":     {"administr", "4", "5", "positions"},
+		"Name size":     {"direito", "2", "7", "name size"},
+		"Same document": {"direito", "2", "2", ""},
+		"Not found":     {"penal", "1", "2", ""},
+		"Name":          {"direito", "2", "1", "name"},
+		"Same phrase":   {"penal militar", "7", "7", ""},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			trieNode := indexTrie()
+			first, second := trieNode.Explain(tc.phrase, tc.first), trieNode.Explain(tc.phrase, tc.second)
+			if key := first.DecidingKey(second); key != tc.expected {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.expected, key)
+			}
+		})
+	}
+}
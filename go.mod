@@ -3,6 +3,10 @@ module trie-go/trie
 go 1.15
 
 require (
+	github.com/golang/protobuf v1.4.1
 	github.com/google/go-cmp v0.5.4
+	golang.org/x/term v0.0.0-20201126162022-7de9c90e9dd1
 	golang.org/x/text v0.3.4
+	google.golang.org/grpc v1.33.2
+	google.golang.org/protobuf v1.25.0
 )
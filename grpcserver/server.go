@@ -0,0 +1,212 @@
+// Package grpcserver implements the gRPC service of triepb backed by a trie
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"trie-go/trie"
+	"trie-go/trie/triepb"
+)
+
+// Default values of the requests that leave the sizes empty
+const (
+	defaultPerPage = 10
+	maxPerPage     = 100
+	defaultK       = 10
+)
+
+// Server is the gRPC service of a trie
+type Server struct {
+	triepb.UnimplementedTrieServiceServer
+	mu     sync.RWMutex
+	trie   *trie.Node
+	remove []string
+}
+
+// New returns the service of the trie, the remove list is used to add every document
+func New(t *trie.Node, remove ...string) *Server {
+	return &Server{trie: t, remove: remove}
+}
+
+// Index adds the documents, a document with an ID already indexed is replaced
+func (s *Server) Index(ctx context.Context, req *triepb.IndexRequest) (*triepb.IndexResponse, error) {
+	for _, doc := range req.GetDocuments() {
+		if doc.GetId() == "" || strings.TrimSpace(doc.GetName()) == "" {
+			return nil, status.Error(codes.InvalidArgument, "every document must have an id and a name")
+		}
+	}
+	resp := &triepb.IndexResponse{}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doc := range req.GetDocuments() {
+		if s.trie.Remove(doc.GetId()) {
+			resp.Replaced++
+		} else {
+			resp.Added++
+		}
+		s.trie.Add(doc.GetId(), doc.GetName(), s.remove...)
+	}
+	return resp, nil
+}
+
+// Delete removes the documents of the IDs, the IDs that are not indexed are ignored
+func (s *Server) Delete(ctx context.Context, req *triepb.DeleteRequest) (*triepb.DeleteResponse, error) {
+	resp := &triepb.DeleteResponse{}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range req.GetIds() {
+		if s.trie.Remove(id) {
+			resp.Deleted++
+		}
+	}
+	return resp, nil
+}
+
+// Search returns a page of the documents of the query ordered by relevance
+func (s *Server) Search(ctx context.Context, req *triepb.SearchRequest) (*triepb.SearchResponse, error) {
+	if strings.TrimSpace(req.GetQuery()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "the query is empty")
+	}
+	page, perPage := req.GetPage(), req.GetPerPage()
+	if page < 0 || perPage < 0 || perPage > maxPerPage || req.GetFacets() < 0 || req.GetMaxCandidates() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "the page, sizes and limits must be positive and the page size at most 100")
+	}
+	if page == 0 {
+		page = 1
+	}
+	if perPage == 0 {
+		perPage = defaultPerPage
+	}
+	opts := trie.SearchOptions{
+		Pagination:    &trie.Pagination{PerPage: perPage, Page: page},
+		Facets:        int(req.GetFacets()),
+		MaxCandidates: int(req.GetMaxCandidates()),
+	}
+	s.mu.RLock()
+	result, err := s.trie.SearchContext(ctx, req.GetQuery(), opts)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, searchError(err)
+	}
+	resp := &triepb.SearchResponse{Total: int32(result.Total), Took: durationpb.New(result.Took)}
+	for _, hit := range result.Hits {
+		positions := make([]int32, len(hit.Positions))
+		for i, position := range hit.Positions {
+			positions[i] = int32(position)
+		}
+		resp.Hits = append(resp.Hits, &triepb.Hit{
+			Id:        hit.ID,
+			Name:      hit.Name,
+			Score:     hit.Score,
+			Terms:     hit.Terms,
+			Positions: positions,
+			MatchType: matchType(hit.MatchType),
+		})
+	}
+	for term, counts := range result.Facets {
+		facet := &triepb.Facet{Term: term}
+		for _, count := range counts {
+			facet.Counts = append(facet.Counts, &triepb.FacetCount{Word: count.Word, Count: int32(count.Count)})
+		}
+		resp.Facets = append(resp.Facets, facet)
+	}
+	sort.Slice(resp.Facets, func(i, j int) bool {
+		return resp.Facets[i].Term < resp.Facets[j].Term
+	})
+	return resp, nil
+}
+
+// Suggest returns the best documents and phrases for a phrase that is being typed
+func (s *Server) Suggest(ctx context.Context, req *triepb.SuggestRequest) (*triepb.SuggestResponse, error) {
+	k, err := size(req.GetK())
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	completion := s.trie.AutocompletePhrase(req.GetInput(), k)
+	s.mu.RUnlock()
+	resp := &triepb.SuggestResponse{Phrases: completion.Phrases}
+	for _, data := range completion.Documents {
+		resp.Documents = append(resp.Documents, &triepb.Document{Id: data.ID, Name: data.Name})
+	}
+	return resp, nil
+}
+
+// Complete returns the words most inserted that start with the prefix
+func (s *Server) Complete(ctx context.Context, req *triepb.CompleteRequest) (*triepb.CompleteResponse, error) {
+	k, err := size(req.GetK())
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	words := s.trie.Complete(req.GetPrefix(), k)
+	s.mu.RUnlock()
+	return &triepb.CompleteResponse{Words: words}, nil
+}
+
+// Stats returns the number of documents and words, every word of the trie is visited
+func (s *Server) Stats(ctx context.Context, req *triepb.StatsRequest) (*triepb.StatsResponse, error) {
+	s.mu.RLock()
+	stats := s.trie.Stats()
+	s.mu.RUnlock()
+	resp := &triepb.StatsResponse{
+		Documents:       int32(stats.Documents),
+		Words:           int32(stats.Words),
+		Nodes:           int32(stats.Nodes),
+		Postings:        int64(stats.Postings),
+		HeapSize:        stats.HeapSize,
+		LargestCorrect:  postingSizes(stats.LargestCorrect),
+		LargestPossible: postingSizes(stats.LargestPossible),
+	}
+	for _, nodes := range stats.Depths {
+		resp.Depths = append(resp.Depths, int32(nodes))
+	}
+	return resp, nil
+}
+
+func postingSizes(sizes []trie.PostingSize) []*triepb.PostingSize {
+	list := make([]*triepb.PostingSize, 0, len(sizes))
+	for _, size := range sizes {
+		list = append(list, &triepb.PostingSize{Prefix: size.Prefix, Ids: int32(size.IDs)})
+	}
+	return list
+}
+
+// size returns the number of items requested or the default when it is zero
+func size(k int32) (int, error) {
+	switch {
+	case k < 0 || k > maxPerPage:
+		return 0, status.Error(codes.InvalidArgument, "k must be between 0 and 100")
+	case k == 0:
+		return defaultK, nil
+	}
+	return int(k), nil
+}
+
+// searchError returns the status of an error of the search
+func searchError(err error) error {
+	switch {
+	case errors.Is(err, trie.ErrTooManyCandidates):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, trie.ErrCanceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func matchType(m trie.MatchType) triepb.MatchType {
+	if m == trie.MatchPrefix {
+		return triepb.MatchType_MATCH_TYPE_PREFIX
+	}
+	return triepb.MatchType_MATCH_TYPE_EXACT
+}
@@ -0,0 +1,191 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+
+	"trie-go/trie"
+	"trie-go/trie/triepb"
+)
+
+func testTrie() *trie.Node {
+	t := trie.NewNode()
+	t.Add("1", "Direito Penal")
+	t.Add("2", "Direito Civil")
+	t.Add("3", "Direção Defensiva")
+	t.Add("4", "Administração Pública")
+	return t
+}
+
+// dial serves the trie in memory and returns a client connected to it
+func dial(t *testing.T, trieNode *trie.Node) triepb.TrieServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	triepb.RegisterTrieServiceServer(server, New(trieNode, "-"))
+	go server.Serve(listener)
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+	return triepb.NewTrieServiceClient(conn)
+}
+
+func Test_Service(t *testing.T) {
+	cases := map[string]struct {
+		call     func(client triepb.TrieServiceClient) (proto.Message, error)
+		expected proto.Message
+		code     codes.Code
+	}{
+		"Index": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Index(context.Background(), &triepb.IndexRequest{Documents: []*triepb.Document{{Id: "5", Name: "Direito-Tributário"}, {Id: "1", Name: "Penal"}}})
+			},
+			expected: &triepb.IndexResponse{Added: 1, Replaced: 1},
+		},
+		"Index without name": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Index(context.Background(), &triepb.IndexRequest{Documents: []*triepb.Document{{Id: "5"}}})
+			},
+			code: codes.InvalidArgument,
+		},
+		"Delete": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Delete(context.Background(), &triepb.DeleteRequest{Ids: []string{"1", "9", "2"}})
+			},
+			expected: &triepb.DeleteResponse{Deleted: 2},
+		},
+		"Search": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Search(context.Background(), &triepb.SearchRequest{Query: "dire", PerPage: 2, Facets: 2})
+			},
+			expected: &triepb.SearchResponse{
+				Hits: []*triepb.Hit{
+					{Id: "2", Name: "Direito Civil", Score: 0.5, Terms: []string{"dire"}, Positions: []int32{0}, MatchType: triepb.MatchType_MATCH_TYPE_PREFIX},
+					{Id: "1", Name: "Direito Penal", Score: 0.5, Terms: []string{"dire"}, Positions: []int32{0}, MatchType: triepb.MatchType_MATCH_TYPE_PREFIX},
+				},
+				Total:  3,
+				Facets: []*triepb.Facet{{Term: "dire", Counts: []*triepb.FacetCount{{Word: "Direito", Count: 2}, {Word: "Direção", Count: 1}}}},
+			},
+		},
+		"Search second page": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Search(context.Background(), &triepb.SearchRequest{Query: "direito", Page: 2, PerPage: 1})
+			},
+			expected: &triepb.SearchResponse{
+				Hits:  []*triepb.Hit{{Id: "1", Name: "Direito Penal", Score: 1, Terms: []string{"direito"}, Positions: []int32{0}, MatchType: triepb.MatchType_MATCH_TYPE_EXACT}},
+				Total: 2,
+			},
+		},
+		"Search empty query": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Search(context.Background(), &triepb.SearchRequest{Query: " "})
+			},
+			code: codes.InvalidArgument,
+		},
+		"Search too many candidates": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Search(context.Background(), &triepb.SearchRequest{Query: "dire", MaxCandidates: 2})
+			},
+			code: codes.ResourceExhausted,
+		},
+		"Search deadline exceeded": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+				defer cancel()
+				return client.Search(ctx, &triepb.SearchRequest{Query: "dire"})
+			},
+			code: codes.DeadlineExceeded,
+		},
+		"Suggest": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Suggest(context.Background(), &triepb.SuggestRequest{Input: "direito p", K: 2})
+			},
+			expected: &triepb.SuggestResponse{Documents: []*triepb.Document{{Id: "1", Name: "Direito Penal"}}, Phrases: []string{"direito Penal"}},
+		},
+		"Suggest invalid k": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Suggest(context.Background(), &triepb.SuggestRequest{Input: "dir", K: -1})
+			},
+			code: codes.InvalidArgument,
+		},
+		"Complete": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Complete(context.Background(), &triepb.CompleteRequest{Prefix: "d"})
+			},
+			expected: &triepb.CompleteResponse{Words: []string{"Direito", "Defensiva", "Direção"}},
+		},
+		"Stats": {
+			call: func(client triepb.TrieServiceClient) (proto.Message, error) {
+				return client.Stats(context.Background(), &triepb.StatsRequest{})
+			},
+			expected: &triepb.StatsResponse{
+				Documents: 4,
+				Words:     7,
+				Nodes:     10,
+				Postings:  16,
+				Depths:    []int32{4, 4, 2},
+				LargestCorrect: []*triepb.PostingSize{
+					{Prefix: "direito", Ids: 2}, {Prefix: "administracao", Ids: 1}, {Prefix: "civil", Ids: 1}, {Prefix: "defensiva", Ids: 1},
+					{Prefix: "direcao", Ids: 1}, {Prefix: "penal", Ids: 1}, {Prefix: "publica", Ids: 1},
+				},
+				LargestPossible: []*triepb.PostingSize{
+					{Prefix: "d", Ids: 3}, {Prefix: "di", Ids: 3}, {Prefix: "dire", Ids: 3}, {Prefix: "direi", Ids: 2}, {Prefix: "p", Ids: 2},
+					{Prefix: "a", Ids: 1}, {Prefix: "c", Ids: 1}, {Prefix: "de", Ids: 1}, {Prefix: "direc", Ids: 1}, {Prefix: "pe", Ids: 1},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			resp, err := tc.call(dial(t, testTrie()))
+			if status.Code(err) != tc.code {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.code, err)
+			}
+			if err != nil {
+				return
+			}
+			diff := cmp.Diff(tc.expected, resp, protocmp.Transform(), protocmp.IgnoreFields(&triepb.SearchResponse{}, "took"), protocmp.IgnoreFields(&triepb.StatsResponse{}, "heap_size"))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_ChangesAreSearched(t *testing.T) {
+	client := dial(t, trie.NewNode())
+	ctx := context.Background()
+	if _, err := client.Index(ctx, &triepb.IndexRequest{Documents: []*triepb.Document{{Id: "1", Name: "Direito Penal"}, {Id: "2", Name: "Direito Civil"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Delete(ctx, &triepb.DeleteRequest{Ids: []string{"1"}}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Search(ctx, &triepb.SearchRequest{Query: "direito"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetHits()) != 1 || resp.GetHits()[0].GetId() != "2" || resp.GetTook() == nil {
+		t.Fatalf("\nExpected: %v\nGot: %v", "the document 2", resp)
+	}
+}
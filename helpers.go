@@ -20,31 +20,6 @@ func getKeyListFromMap(m map[string]int) []string {
 	return keys
 }
 
-func getKeyListFromObjMap(m map[string]*internalOrderData) []string {
-	keys := make([]string, len(m))
-	i := 0
-	for k := range m {
-		keys[i] = k
-		i++
-	}
-	return keys
-}
-
-func orderMapByRelevance(m map[string]*internalOrderData) []SearchData {
-	values := make([]*internalOrderData, len(m))
-	i := 0
-	for _, v := range m {
-		values[i] = v
-		i++
-	}
-	sort.Sort(byRelevance(values))
-	var orderedSearchData []SearchData
-	for _, value := range values {
-		orderedSearchData = append(orderedSearchData, SearchData{ID: value.id, Name: value.name})
-	}
-	return orderedSearchData
-}
-
 func getKeyListOrderedFromMap(m map[string]int) []string {
 	type kv struct {
 		key   string
@@ -75,6 +50,11 @@ func removeStringList(value string, removeStrings ...string) string {
 	return value
 }
 
+// CleanWord returns the word in the form the trie indexes it, in lower case, without accents and the characters that are not letters or digits
+func CleanWord(word string) string {
+	return cleanString(word)
+}
+
 func cleanString(value string) string {
 	lowerCase := strings.ToLower(value)
 	t := transform.Chain(norm.NFD, transform.RemoveFunc(isMn), norm.NFC)
@@ -82,7 +62,7 @@ func cleanString(value string) string {
 	return rxp.ReplaceAllString(newValue, "")
 }
 
-func paginateList(list []SearchData, pagination Pagination) ([]SearchData, Pagination) {
+func paginateList(list byRelevance, pagination Pagination) (byRelevance, Pagination) {
 	if len(list) == 0 || pagination.Offset() >= len(list) {
 		return nil, pagination
 	}
@@ -121,3 +101,28 @@ func dist(i, j []int) int {
 	}
 	return 1
 }
+
+// insertPosition inserts the position in the sorted positions, a position that is already there is not repeated
+func insertPosition(positions []int, position int) []int {
+	i := sort.SearchInts(positions, position)
+	if i < len(positions) && positions[i] == position {
+		return positions
+	}
+	positions = append(positions, 0)
+	copy(positions[i+1:], positions[i:])
+	positions[i] = position
+	return positions
+}
+
+// mergePositions returns the sorted union of both position lists without repeated values
+func mergePositions(i, j []int) []int {
+	merged := append(append([]int{}, i...), j...)
+	sort.Ints(merged)
+	var unique []int
+	for _, position := range merged {
+		if len(unique) == 0 || unique[len(unique)-1] != position {
+			unique = append(unique, position)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,128 @@
+package trie
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchByRelevanceHighlighted return the same data as SearchByRelevance with the matches of the phrase in every name
+// the remove list must be the same one used to add the data, so the words of the name are split in the same way
+func (t *Node) SearchByRelevanceHighlighted(phrase string, remove ...string) []HighlightedData {
+	var highlighted []HighlightedData
+	for _, data := range t.SearchByRelevance(phrase) {
+		highlighted = append(highlighted, HighlightedData{SearchData: data, Matches: FindMatches(data.Name, phrase, remove...)})
+	}
+	return highlighted
+}
+
+// FindMatches returns the offsets of the words of the name that match a word of the phrase
+// a word matches when it is equal to a word of the phrase or when it starts with it, only the matched part is returned
+// the words are compared after being cleaned in the same way they are added in the trie
+func FindMatches(name, phrase string, remove ...string) []Match {
+	var queries []string
+	for _, word := range strings.Fields(phrase) {
+		cleanedString := cleanString(word)
+		if len(cleanedString) >= minWordSize {
+			queries = append(queries, cleanedString)
+		}
+	}
+	var matches []Match
+	for _, span := range wordSpans(name, remove) {
+		cleanedRunes := cleanRunes(name[span[0]:span[1]])
+		cleanedString := strings.Join(cleanedRunes, "")
+		if len(cleanedString) < minWordSize {
+			continue
+		}
+		longest := ""
+		for _, query := range queries {
+			if strings.HasPrefix(cleanedString, query) && len(query) > len(longest) {
+				longest = query
+			}
+		}
+		if longest == "" {
+			continue
+		}
+		match := Match{Start: -1, Exact: longest == cleanedString}
+		size := 0
+		for i, runeValue := range name[span[0]:span[1]] {
+			cleaned := cleanedRunes[0]
+			cleanedRunes = cleanedRunes[1:]
+			if cleaned == "" {
+				continue
+			}
+			if match.Start < 0 {
+				match.Start = span[0] + i
+			}
+			size += len(cleaned)
+			if size >= len(longest) {
+				match.End = span[0] + i + utf8.RuneLen(runeValue)
+				break
+			}
+		}
+		match.RuneStart = utf8.RuneCountInString(name[:match.Start])
+		match.RuneEnd = match.RuneStart + utf8.RuneCountInString(name[match.Start:match.End])
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// Highlight returns the name with the matches surrounded by the pre and post tags
+func Highlight(name string, matches []Match, pre, post string) string {
+	sorted := append([]Match{}, matches...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+	var builder strings.Builder
+	last := 0
+	for _, match := range sorted {
+		if match.Start < last || match.End > len(name) {
+			continue
+		}
+		builder.WriteString(name[last:match.Start])
+		builder.WriteString(pre)
+		builder.WriteString(name[match.Start:match.End])
+		builder.WriteString(post)
+		last = match.End
+	}
+	builder.WriteString(name[last:])
+	return builder.String()
+}
+
+// wordSpans returns the byte offsets of the words of the name, the same words the Add method inserts in the trie
+// the removed patterns are replaced by spaces with the same size, so the offsets are kept
+func wordSpans(name string, remove []string) [][2]int {
+	work := name
+	for _, pattern := range remove {
+		if pattern == "" {
+			continue
+		}
+		work = strings.ReplaceAll(work, pattern, strings.Repeat(" ", len(pattern)))
+	}
+	var spans [][2]int
+	start := -1
+	for i, runeValue := range work {
+		if unicode.IsSpace(runeValue) {
+			if start >= 0 {
+				spans = append(spans, [2]int{start, i})
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(work)})
+	}
+	return spans
+}
+
+// cleanRunes returns every rune of the word cleaned, the removed runes are empty strings
+func cleanRunes(word string) []string {
+	var cleaned []string
+	for _, runeValue := range word {
+		cleaned = append(cleaned, cleanString(string(runeValue)))
+	}
+	return cleaned
+}
@@ -0,0 +1,70 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_FindMatches(t *testing.T) {
+	cases := map[string]struct {
+		name     string
+		phrase   string
+		remove   []string
+		expected []Match
+	}{
+		"Exact word":              {"Direito Penal", "penal", nil, []Match{{8, 13, 8, 13, true}}},
+		"Prefix of a word":        {"Direito Penal", "dir", nil, []Match{{0, 3, 0, 3, false}}},
+		"Multiple words":          {"Direito Penal / Direito", "direito pen", nil, []Match{{0, 7, 0, 7, true}, {8, 11, 8, 11, false}, {16, 23, 16, 23, true}}},
+		"Accents in the name":     {"Administração Pública", "administracao publ", nil, []Match{{0, 15, 0, 13, true}, {16, 21, 14, 18, false}}},
+		"Punctuation is not part": {"(Direito), Penal", "direito", nil, []Match{{1, 8, 1, 8, true}}},
+		"Removed patterns":        {"Direito::Penal", "penal", []string{"::"}, []Match{{9, 14, 9, 14, true}}},
+		"Small words are ignored": {"Direito do Estado", "do", nil, nil},
+		"No match":                {"Direito Penal", "civil", nil, nil},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expected, FindMatches(tc.name, tc.phrase, tc.remove...))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_Highlight(t *testing.T) {
+	cases := map[string]struct {
+		name     string
+		phrase   string
+		expected string
+	}{
+		"Exact and prefix":    {"Direito Penal Militar", "direito mil", "<b>Direito</b> Penal <b>Mil</b>itar"},
+		"Accents in the name": {"Administração Pública", "administracao", "<b>Administração</b> Pública"},
+		"No match":            {"Direito Penal", "civil", "Direito Penal"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			highlighted := Highlight(tc.name, FindMatches(tc.name, tc.phrase), "<b>", "</b>")
+			if highlighted != tc.expected {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.expected, highlighted)
+			}
+		})
+	}
+}
+
+func Test_SearchByRelevanceHighlighted(t *testing.T) {
+	trieNode := NewNode()
+	trieNode.Add("1", "Direito Penal")
+	trieNode.Add("2", "Direito/Penal Militar", "/")
+
+	expected := []HighlightedData{
+		{SearchData{"1", "Direito Penal"}, []Match{{0, 7, 0, 7, true}, {8, 11, 8, 11, false}}},
+		{SearchData{"2", "Direito/Penal Militar"}, []Match{{0, 7, 0, 7, true}, {8, 11, 8, 11, false}}},
+	}
+	diff := cmp.Diff(expected, trieNode.SearchByRelevanceHighlighted("direito pen", "/"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
@@ -1,53 +1,96 @@
 package trie
 
 import (
-	"fmt"
-	"sort"
+	"context"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // Add will insert a new TrieObject in the Trie
 func (t *Node) Add(id, name string, remove ...string) {
+	var start time.Time
+	if t.observer != nil {
+		start = time.Now()
+	}
+	words := analyze(name, remove)
+	t.insertDocument(id, name, words)
+	if t.observer != nil {
+		t.observer.ObserveAdd(len(words), len(t.documents.ordinals), time.Since(start))
+	}
+}
+
+// analyze returns the words of the name that are inserted in the trie, with their cleaned form and position
+func analyze(name string, remove []string) []analyzedWord {
+	var words []analyzedWord
 	for position, word := range strings.Fields(removeStringList(name, remove...)) {
 		cleanedString := cleanString(word)
 		if len(cleanedString) < minWordSize {
 			continue
 		}
-		node := t
-		for i, runeValue := range cleanedString {
-			if _, ok := node.children[runeValue]; !ok {
-				child := &Node{
-					currentWord:   cleanedString[:i+1],
-					children:      make(map[rune]*Node),
-					correctWords:  make(map[string]int),
-					possibleWords: make(map[string]int),
-					possibleData:  make(map[string]*internalOrderData),
-					correctData:   make(map[string]*internalOrderData),
-				}
-				node.children[runeValue] = child
-			}
-			node = node.children[runeValue]
-			if len(cleanedString[i+1:]) == 0 {
+		words = append(words, analyzedWord{word: word, cleaned: cleanedString, position: position})
+	}
+	return words
+}
+
+// joinWords returns the original words separated by spaces
+func joinWords(words []analyzedWord) string {
+	original := make([]string, len(words))
+	for i, w := range words {
+		original[i] = w.word
+	}
+	return strings.Join(original, " ")
+}
+
+// insertDocument inserts the analyzed words of the document, a document without words is not added
+func (t *Node) insertDocument(id, name string, words []analyzedWord) {
+	if len(words) == 0 {
+		return
+	}
+	doc := t.documents.ordinal(id, name)
+	t.documents.addWords(doc, joinWords(words))
+	for _, w := range words {
+		t.insert(doc, w.word, w.cleaned, w.position)
+		t.insertSuffixes(doc, w.word, w.cleaned, w.position)
+	}
+}
+
+// insert will add the cleaned word in the trie, the nodes are compressed, so a node is only created
+// where a word ends or where the words split in different children
+func (t *Node) insert(doc uint32, word, cleanedString string, position int) {
+	node := t
+	for {
+		rest := cleanedString[len(node.currentWord):]
+		if node != t {
+			if len(rest) == 0 {
 				node.isWord = true
-				if _, ok := node.correctData[id]; ok {
-					data := node.correctData[id]
-					data.position = append(data.position, position)
-					node.correctData[id] = data
-				} else {
-					node.correctData[id] = &internalOrderData{id: id, name: name, position: []int{position}}
+				if node.correctWords == nil {
+					node.correctWords = make(map[string]int)
 				}
+				node.correctData.add(doc, position)
 				node.correctWords[word]++
-			} else {
-				if _, ok := node.possibleData[id]; ok {
-					data := node.possibleData[id]
-					data.position = append(data.position, position)
-					node.possibleData[id] = data
-				} else {
-					node.possibleData[id] = &internalOrderData{id: id, name: name, position: []int{position}}
-				}
-				node.possibleWords[word]++
+				node.updateTopWords(word, node.correctWords[word])
+				return
 			}
+			if node.possibleWords == nil {
+				node.possibleWords = make(map[string]int)
+			}
+			node.possibleData.add(doc, position)
+			node.possibleWords[word]++
+			node.updateTopWords(word, node.possibleWords[word])
+		}
+		runeValue, _ := utf8.DecodeRuneInString(rest)
+		child, ok := node.children[runeValue]
+		if !ok {
+			child = &Node{currentWord: cleanedString}
+		} else if size := commonPrefixLength(child.label(node), rest); size < len(child.label(node)) {
+			child = splitNode(child, cleanedString[:len(node.currentWord)+size])
 		}
+		if node.children == nil {
+			node.children = make(map[rune]*Node)
+		}
+		node.children[runeValue] = child
+		node = child
 	}
 }
 
@@ -58,125 +101,128 @@ func (t *Node) IsFilled() bool {
 
 // HasWord return a boolean value if the word is recorded in the trie
 func (t *Node) HasWord(word string) bool {
-	node := t
-	for _, runeValue := range cleanString(word) {
-		if _, ok := node.children[runeValue]; !ok {
-			return false
-		}
-		node = node.children[runeValue]
+	node := t.getNode(cleanString(word))
+	if node == nil {
+		return false
 	}
 	return node.isWord
 }
 
 // GetPossibleWords return the possible words for the word parameter
 func (t *Node) GetPossibleWords(word string) []string {
-	node := t
-	for _, runeValue := range cleanString(word) {
-		if _, ok := node.children[runeValue]; !ok {
-			return nil
-		}
-		node = node.children[runeValue]
+	node := t.getNode(cleanString(word))
+	if node == nil {
+		return nil
 	}
 	return getKeyListOrderedFromMap(node.possibleWords)
 }
 
 // GetCorrectWords return the matching words for the word parameter
 func (t *Node) GetCorrectWords(word string) []string {
-	node := t
-	for _, runeValue := range cleanString(word) {
-		if _, ok := node.children[runeValue]; !ok {
-			return nil
-		}
-		node = node.children[runeValue]
+	node := t.getNode(cleanString(word))
+	if node == nil {
+		return nil
 	}
 	return getKeyListOrderedFromMap(node.correctWords)
 }
 
 // GetCorrectIDs return the matching IDs for the word parameter
 func (t *Node) GetCorrectIDs(word string) []string {
-	node := t
-	for _, runeValue := range cleanString(word) {
-		if _, ok := node.children[runeValue]; !ok {
-			return nil
-		}
-		node = node.children[runeValue]
+	node := t.getNode(cleanString(word))
+	if node == nil {
+		return nil
 	}
-	return getKeyListFromObjMap(node.correctData)
+	return idList(t.documents, &node.correctData)
 }
 
 // GetPossibleIDs return the matching IDs for the word parameter
 func (t *Node) GetPossibleIDs(word string) []string {
-	node := t
-	for _, runeValue := range cleanString(word) {
-		if _, ok := node.children[runeValue]; !ok {
-			return nil
-		}
-		node = node.children[runeValue]
+	node := t.getNode(cleanString(word))
+	if node == nil {
+		return nil
 	}
-	return getKeyListFromObjMap(node.possibleData)
-}
-
-// PrintWordData will print the data of a node
-func (t *Node) PrintWordData(word string) {
-	node := t
-	for _, runeValue := range cleanString(word) {
-		if _, ok := node.children[runeValue]; !ok {
-			return
-		}
-		node = node.children[runeValue]
-	}
-	fmt.Println(node.correctData)
-	return
+	return idList(t.documents, &node.possibleData)
 }
 
 // SearchByRelevance return the matching IDs for the word parameter ordered by the complete name data and the distance of the searched data
 func (t *Node) SearchByRelevance(phrase string) []SearchData {
+	result, _ := t.SearchContext(context.Background(), phrase, SearchOptions{})
+	return result.searchData()
+}
+
+func (t *Node) searchPostings(phrase string) *postings {
+	_, p, _ := t.searchTerms(context.Background(), phrase, 0, nil)
+	return p
+}
+
+// searchTerms returns the terms of the phrase and their documents, the deepest node of every word is used
+// visit, when it is not nil, receives every word before the postings are intersected
+// the postings are not intersected when a word has more documents than the maximum of candidates, zero has no maximum
+func (t *Node) searchTerms(ctx context.Context, phrase string, maxCandidates int, visit termVisitor) ([]queryTerm, *postings, error) {
 	var nodes []*Node
 	for _, word := range strings.Fields(phrase) {
 		cleanedString := cleanString(word)
 		if len(cleanedString) < minWordSize {
 			continue
 		}
-		node := t
-		for _, runeValue := range cleanedString {
-			if _, ok := node.children[runeValue]; !ok {
-				break
-			}
-			node = node.children[runeValue]
+		node := t.getDeepestNode(cleanedString)
+		if visit != nil {
+			visit(word, node.currentWord, node.correctData.len() > 0, node.relevantPostings())
 		}
 		nodes = append(nodes, node)
 	}
-	return orderMapByRelevance(intersectNodes(nodes))
+	p, err := intersectNodes(ctx, nodes, maxCandidates)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nodeTerms(nodes), p, nil
+}
+
+// nodeTerms returns the terms of the nodes that have documents, a node is exact when its correct words are intersected
+func nodeTerms(nodes []*Node) []queryTerm {
+	var terms []queryTerm
+	for _, node := range nodes {
+		if node.correctData.len() == 0 && node.possibleData.len() == 0 {
+			continue
+		}
+		node := node
+		terms = append(terms, queryTerm{
+			word:  node.currentWord,
+			exact: node.correctData.len() > 0,
+			each: func(visit func(words map[string]int, p *postings)) {
+				node.walkWords(func(word *Node) {
+					visit(word.correctWords, &word.correctData)
+				})
+			},
+		})
+	}
+	return terms
 }
 
 // SearchByRelevancePaginated return the matching IDs for the word parameter ordered by the complete name data and paginates the result
 func (t *Node) SearchByRelevancePaginated(phrase string, pagination Pagination) ([]SearchData, Pagination) {
-	return paginateList(t.SearchByRelevance(phrase), pagination)
+	result, _ := t.SearchContext(context.Background(), phrase, SearchOptions{Pagination: &pagination})
+	return result.searchData(), result.Pagination
 }
 
-func intersectNodes(nodes []*Node) map[string]*internalOrderData {
-	var finalKeys map[string]*internalOrderData
-	for _, node := range nodes {
-		data := node.correctData
-		if len(data) == 0 {
-			data = node.possibleData
-		}
-		if finalKeys == nil {
-			finalKeys = data
-			continue
-		}
-		intermediateKeys := make(map[string]*internalOrderData)
-		for key, values := range data {
-			if value, ok := finalKeys[key]; ok && value != nil {
-				intSlice := sort.IntSlice(append(finalKeys[key].position, values.position...))
-				sort.Sort(intSlice)
-				values.position = intSlice
-				intermediateKeys[key] = values
-			}
-		}
-		finalKeys = intermediateKeys
+// intersectNodes returns the documents in all the nodes, the correct words are used and if there is none the possible ones
+func intersectNodes(ctx context.Context, nodes []*Node, maxCandidates int) (*postings, error) {
+	postingList := make([]*postings, len(nodes))
+	for i, node := range nodes {
+		postingList[i] = node.relevantPostings()
 	}
-	return finalKeys
+	return intersectCandidates(ctx, postingList, maxCandidates)
+}
+
+// relevantPostings returns the postings of the correct words of the node and if there is none the possible ones
+func (t *Node) relevantPostings() *postings {
+	switch {
+	case t.correctData.len() > 0:
+		return &t.correctData
+	case t.possibleData.len() > 0:
+		return &t.possibleData
+	}
+	return nil
 }
 
 // GetMaximumSizeOfPossibleIds returns the maximum size of ids for the possible words in a node
@@ -186,15 +232,21 @@ func (t *Node) GetMaximumSizeOfPossibleIds() int {
 
 func getMaximumSizeOfPossibleIds(max int, t *Node) int {
 	if len(t.children) == 0 {
-		return len(t.possibleData)
+		return t.possibleData.len()
 	}
 	for _, child := range t.children {
 		if new := getMaximumSizeOfPossibleIds(max, child); new > max {
 			max = new
 		}
+		// the positions compressed inside the edge have the possible and correct IDs of the child
+		if utf8.RuneCountInString(child.label(t)) > 1 {
+			if new := or(&child.possibleData.docs, &child.correctData.docs).cardinality(); new > max {
+				max = new
+			}
+		}
 	}
-	if len(t.possibleData) > max {
-		max = len(t.possibleData)
+	if t.possibleData.len() > max {
+		max = t.possibleData.len()
 	}
 	return max
 }
@@ -206,27 +258,15 @@ func (t *Node) GetMaximumSizeOfCorrectIds() int {
 
 func getMaximumSizeOfCorrectIds(max int, t *Node) int {
 	if len(t.children) == 0 {
-		return len(t.correctData)
+		return t.correctData.len()
 	}
 	for _, child := range t.children {
 		if new := getMaximumSizeOfCorrectIds(max, child); new > max {
 			max = new
 		}
 	}
-	if len(t.correctData) > max {
-		println(t.currentWord, len(t.correctData))
-		max = len(t.correctData)
+	if t.correctData.len() > max {
+		max = t.correctData.len()
 	}
 	return max
 }
-
-// PrintPathToWord returns the maximum size of ids for the correct words in a node
-func (t *Node) PrintPathToWord(word string) {
-	node := t
-	fmt.Println("Printing word:", word)
-	cleanedString := cleanString(word)
-	for _, runeValue := range cleanedString {
-		node = node.children[runeValue]
-		fmt.Println(string(runeValue), node.currentWord, node.possibleWords)
-	}
-}
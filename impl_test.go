@@ -1,6 +1,7 @@
 package trie
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -207,7 +208,7 @@ func Test_SearchByRelevance(t *testing.T) {
 		{"Adding one word 7", "7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano", "direito penal", []SearchData{{"1", "Direito Penal"}, {"2", "Direito Penal Militar"}, {"3", "Direito Penal / Princípios do Direito Penal"}, {"4", "Direito Penal / Introdução ao estudo do Direito Penal"}, {"5", "Direito Penal / Introdução ao estudo do Direito Penal / O Direito Penal e o Estado Democrático de Direito"}, {"6", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Grego"}, {"7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano"}}},
 		{"Adding one word 8", "8", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal e o IIuminismo", "direito penal", []SearchData{{"1", "Direito Penal"}, {"2", "Direito Penal Militar"}, {"3", "Direito Penal / Princípios do Direito Penal"}, {"4", "Direito Penal / Introdução ao estudo do Direito Penal"}, {"5", "Direito Penal / Introdução ao estudo do Direito Penal / O Direito Penal e o Estado Democrático de Direito"}, {"6", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Grego"}, {"7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano"}, {"8", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal e o IIuminismo"}}},
 		{"Adding one word 9", "9", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal na Idade Média", "direito penal", []SearchData{{"1", "Direito Penal"}, {"2", "Direito Penal Militar"}, {"3", "Direito Penal / Princípios do Direito Penal"}, {"4", "Direito Penal / Introdução ao estudo do Direito Penal"}, {"5", "Direito Penal / Introdução ao estudo do Direito Penal / O Direito Penal e o Estado Democrático de Direito"}, {"6", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Grego"}, {"7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano"}, {"8", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal e o IIuminismo"}, {"9", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal na Idade Média"}}},
-		{"Adding one word 10", "10", "Direito Penal / Introdução ao estudo do Direito Penal / As Velocidades do Direito Penal", "direito penal", []SearchData{{"1", "Direito Penal"}, {"2", "Direito Penal Militar"}, {"3", "Direito Penal / Princípios do Direito Penal"}, {"4", "Direito Penal / Introdução ao estudo do Direito Penal"}, {"5", "Direito Penal / Introdução ao estudo do Direito Penal / O Direito Penal e o Estado Democrático de Direito"}, {"6", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Grego"}, {"7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano"}, {"8", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal e o IIuminismo"}, {"9", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal na Idade Média"}, {"10", "Direito Penal / Introdução ao estudo do Direito Penal / As Velocidades do Direito Penal"}}},
+		{"Adding one word 10", "10", "Direito Penal / Introdução ao estudo do Direito Penal / As Velocidades do Direito Penal", "direito penal", []SearchData{{"1", "Direito Penal"}, {"2", "Direito Penal Militar"}, {"3", "Direito Penal / Princípios do Direito Penal"}, {"4", "Direito Penal / Introdução ao estudo do Direito Penal"}, {"5", "Direito Penal / Introdução ao estudo do Direito Penal / O Direito Penal e o Estado Democrático de Direito"}, {"10", "Direito Penal / Introdução ao estudo do Direito Penal / As Velocidades do Direito Penal"}, {"6", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Grego"}, {"7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano"}, {"8", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal e o IIuminismo"}, {"9", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal na Idade Média"}}},
 	}
 
 	for _, tc := range cases {
@@ -241,7 +242,7 @@ func Test_SearchByRelevancePaginated(t *testing.T) {
 		{"Adding one word 7", "7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano", "direito penal", Pagination{PerPage: 3, Page: 2}, []SearchData{{"4", "Direito Penal / Introdução ao estudo do Direito Penal"}, {"5", "Direito Penal / Introdução ao estudo do Direito Penal / O Direito Penal e o Estado Democrático de Direito"}, {"6", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Grego"}}, Pagination{PerPage: 3, Page: 2, Total: 7}},
 		{"Adding one word 8", "8", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal e o IIuminismo", "direito penal", Pagination{PerPage: 3, Page: 3}, []SearchData{{"7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano"}, {"8", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal e o IIuminismo"}}, Pagination{PerPage: 3, Page: 3, Total: 8}},
 		{"Adding one word 9", "9", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal na Idade Média", "direito penal", Pagination{PerPage: 10, Page: 2}, nil, Pagination{PerPage: 10, Page: 2}},
-		{"Adding one word 10", "10", "Direito Penal / Introdução ao estudo do Direito Penal / As Velocidades do Direito Penal", "direito penal", Pagination{PerPage: 100, Page: 1}, []SearchData{{"1", "Direito Penal"}, {"2", "Direito Penal Militar"}, {"3", "Direito Penal / Princípios do Direito Penal"}, {"4", "Direito Penal / Introdução ao estudo do Direito Penal"}, {"5", "Direito Penal / Introdução ao estudo do Direito Penal / O Direito Penal e o Estado Democrático de Direito"}, {"6", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Grego"}, {"7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano"}, {"8", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal e o IIuminismo"}, {"9", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal na Idade Média"}, {"10", "Direito Penal / Introdução ao estudo do Direito Penal / As Velocidades do Direito Penal"}}, Pagination{PerPage: 100, Page: 1, Total: 10}},
+		{"Adding one word 10", "10", "Direito Penal / Introdução ao estudo do Direito Penal / As Velocidades do Direito Penal", "direito penal", Pagination{PerPage: 100, Page: 1}, []SearchData{{"1", "Direito Penal"}, {"2", "Direito Penal Militar"}, {"3", "Direito Penal / Princípios do Direito Penal"}, {"4", "Direito Penal / Introdução ao estudo do Direito Penal"}, {"5", "Direito Penal / Introdução ao estudo do Direito Penal / O Direito Penal e o Estado Democrático de Direito"}, {"10", "Direito Penal / Introdução ao estudo do Direito Penal / As Velocidades do Direito Penal"}, {"6", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Grego"}, {"7", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal Romano"}, {"8", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal e o IIuminismo"}, {"9", "Direito Penal / Introdução ao estudo do Direito Penal / Evolução Histórica / Direito Penal na Idade Média"}}, Pagination{PerPage: 100, Page: 1, Total: 10}},
 	}
 
 	for _, tc := range cases {
@@ -259,3 +260,30 @@ func Test_SearchByRelevancePaginated(t *testing.T) {
 		})
 	}
 }
+
+func Test_SearchDoesNotChangeTrie(t *testing.T) {
+	trieNode := NewNode()
+	trieNode.Add("1", "Direito Penal")
+	trieNode.Add("2", "Código Penal")
+	trieNode.Add("3", "Penal Direito")
+	var before bytes.Buffer
+	if err := trieNode.WriteIndex(&before); err != nil {
+		t.Fatal(err)
+	}
+	expected := trieNode.SearchByRelevance("penal")
+	for i := 0; i < 3; i++ {
+		trieNode.SearchByRelevance("direito penal")
+		trieNode.SearchByRelevancePaginated("penal direito", Pagination{PerPage: 1, Page: 1})
+	}
+	diff := cmp.Diff(expected, trieNode.SearchByRelevance("penal"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	var after bytes.Buffer
+	if err := trieNode.WriteIndex(&after); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before.Bytes(), after.Bytes()) {
+		t.Fatalf("\nExpected: %v\nGot: %v", before.Len(), after.Len())
+	}
+}
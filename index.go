@@ -0,0 +1,288 @@
+package trie
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// IsFilled return a boolean value if the index has any word
+func (idx *Index) IsFilled() bool {
+	return idx.words.size > 0
+}
+
+// HasWord return a boolean value if the word is recorded in the index
+func (idx *Index) HasWord(word string) bool {
+	r, ok := idx.words.lookup(cleanString(word))
+	return ok && r.isWord
+}
+
+// GetCorrectIDs return the matching IDs for the word parameter, only the documents of the word itself are returned
+func (idx *Index) GetCorrectIDs(word string) []string {
+	r, ok := idx.words.lookup(cleanString(word))
+	if !ok || !r.isWord {
+		return nil
+	}
+	return idList(idx.documents, idx.words.postingsOf(r.first))
+}
+
+// GetPossibleWords return the possible words for the word parameter
+func (idx *Index) GetPossibleWords(word string) []string {
+	r, ok := idx.words.lookup(cleanString(word))
+	if !ok || r.prefix == "" {
+		return nil
+	}
+	words := make(map[string]int)
+	for ordinal := r.first; ordinal < r.last; ordinal++ {
+		if ordinal == r.first && r.isWord {
+			continue
+		}
+		for word, count := range idx.words.wordsOf(ordinal) {
+			words[word] += count
+		}
+	}
+	return getKeyListOrderedFromMap(words)
+}
+
+// Complete return the k words most inserted that start with the prefix, the prefix itself is included if it is a word
+// The index does not keep the best completions of every prefix, so every word of the prefix is read
+func (idx *Index) Complete(prefix string, k int) []string {
+	r, ok := idx.words.lookup(cleanString(prefix))
+	if k <= 0 || !ok || r.prefix == "" {
+		return nil
+	}
+	var wordMaps []map[string]int
+	for ordinal := r.first; ordinal < r.last; ordinal++ {
+		wordMaps = append(wordMaps, idx.words.wordsOf(ordinal))
+	}
+	return selectTopWords(k, wordMaps...)
+}
+
+// AutocompletePhrase return the best documents and phrase suggestions for a phrase that is still being typed
+// It is the same as the AutocompletePhrase of the trie
+func (idx *Index) AutocompletePhrase(input string, k int) PhraseCompletion {
+	words := strings.Fields(input)
+	if k <= 0 || len(words) == 0 {
+		return PhraseCompletion{}
+	}
+	var postingList []*postings
+	for _, word := range words[:len(words)-1] {
+		cleanedString := cleanString(word)
+		if len(cleanedString) < minWordSize {
+			continue
+		}
+		r, ok := idx.words.lookup(cleanedString)
+		if !ok || !r.isWord {
+			return PhraseCompletion{}
+		}
+		postingList = append(postingList, idx.words.postingsOf(r.first))
+	}
+	lastWord := cleanString(words[len(words)-1])
+	r, ok := idx.words.lookup(lastWord)
+	if lastWord == "" || !ok {
+		return PhraseCompletion{}
+	}
+	exact := intersectPostingList(postingList)
+	documents := orderByRelevance(idx.documents, intersectPostingList([]*postings{exact, idx.words.union(r.first, r.last)}))
+	if len(documents) > k {
+		documents = documents[:k]
+	}
+	phrase := strings.Join(words[:len(words)-1], " ")
+	counts := make(map[string]int)
+	for ordinal := r.first; ordinal < r.last; ordinal++ {
+		countPhrase(counts, phrase, idx.words.wordsOf(ordinal), idx.words.postingsOf(ordinal), exact)
+	}
+	return PhraseCompletion{Documents: documents, Phrases: selectTopWords(k, counts)}
+}
+
+// SearchByRelevance return the matching IDs for the word parameter ordered by the complete name data and the distance of the searched data
+func (idx *Index) SearchByRelevance(phrase string) []SearchData {
+	result, _ := idx.SearchContext(context.Background(), phrase, SearchOptions{})
+	return result.searchData()
+}
+
+func (idx *Index) searchPostings(phrase string) *postings {
+	_, p, _ := idx.searchTerms(context.Background(), phrase, 0, nil)
+	return p
+}
+
+// searchTerms returns the terms of the phrase and their documents, the longest prefix of every word is used
+// visit, when it is not nil, receives every word before the postings are intersected
+// the postings are not intersected when a word has more documents than the maximum of candidates, zero has no maximum
+func (idx *Index) searchTerms(ctx context.Context, phrase string, maxCandidates int, visit termVisitor) ([]queryTerm, *postings, error) {
+	var terms []queryTerm
+	var postingList []*postings
+	for _, word := range strings.Fields(phrase) {
+		cleanedString := cleanString(word)
+		if len(cleanedString) < minWordSize {
+			continue
+		}
+		r := idx.words.automaton.seek(cleanedString)
+		p, err := idx.words.relevantPostings(ctx, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if visit != nil {
+			visit(word, r.prefix, r.isWord, p)
+		}
+		if p != nil {
+			terms = append(terms, idx.words.term(r))
+		}
+		postingList = append(postingList, p)
+	}
+	p, err := intersectCandidates(ctx, postingList, maxCandidates)
+	if err != nil {
+		return nil, nil, err
+	}
+	return terms, p, nil
+}
+
+// IterSearch return an iterator over the same results of SearchByRelevance, in the same order
+func (idx *Index) IterSearch(phrase string) Iterator {
+	return newRelevanceIterator(idx.documents, idx.searchPostings(phrase))
+}
+
+// WalkPrefix visits the words that start with the prefix in increasing order with the IDs of the documents of every word
+// only the record of the word being visited is decoded
+func (idx *Index) WalkPrefix(prefix string, visit func(word string, ids []string) bool) {
+	r, ok := idx.words.lookup(cleanString(prefix))
+	if !ok {
+		return
+	}
+	for ordinal := r.first; ordinal < r.last; ordinal++ {
+		if !visit(mostInserted(idx.words.wordsOf(ordinal)), idList(idx.documents, idx.words.postingsOf(ordinal))) {
+			return
+		}
+	}
+}
+
+// SearchByRelevancePaginated return the matching IDs for the word parameter ordered by the complete name data and paginates the result
+func (idx *Index) SearchByRelevancePaginated(phrase string, pagination Pagination) ([]SearchData, Pagination) {
+	result, _ := idx.SearchContext(context.Background(), phrase, SearchOptions{Pagination: &pagination})
+	return result.searchData(), result.Pagination
+}
+
+// SearchByRelevanceHighlighted return the same data as SearchByRelevance with the matches of the phrase in every name
+func (idx *Index) SearchByRelevanceHighlighted(phrase string, remove ...string) []HighlightedData {
+	var highlighted []HighlightedData
+	for _, data := range idx.SearchByRelevance(phrase) {
+		highlighted = append(highlighted, HighlightedData{SearchData: data, Matches: FindMatches(data.Name, phrase, remove...)})
+	}
+	return highlighted
+}
+
+// SearchInfix return the matching IDs for the fragments in the phrase, a fragment may be found in the beginning, middle or end of a word
+func (idx *Index) SearchInfix(phrase string) []SearchData {
+	var postingList []*postings
+	for _, fragment := range strings.Fields(phrase) {
+		cleanedString := cleanString(fragment)
+		if len(cleanedString) < minWordSize {
+			continue
+		}
+		var found []*postings
+		for _, section := range []*indexSection{&idx.words, &idx.suffixes} {
+			if r, ok := section.lookup(cleanedString); ok {
+				found = append(found, section.union(r.first, r.last))
+			}
+		}
+		postingList = append(postingList, unionPostingList(found))
+	}
+	return orderByRelevance(idx.documents, intersectPostingList(postingList))
+}
+
+// SearchPattern return the matching IDs for a phrase of wildcard patterns
+// only the words that start with the literal prefix of a pattern are compared with it
+func (idx *Index) SearchPattern(phrase string) []SearchData {
+	var postingList []*postings
+	for _, word := range strings.Fields(phrase) {
+		pattern := cleanPattern(word)
+		if len(pattern) == 0 {
+			continue
+		}
+		prefix := 0
+		for prefix < len(pattern) && pattern[prefix] != anyRunes && pattern[prefix] != anyRune {
+			prefix++
+		}
+		var found []*postings
+		if r, ok := idx.words.lookup(string(pattern[:prefix])); ok {
+			idx.words.automaton.each(r, func(word string, ordinal int) bool {
+				states := stepPattern(pattern, nil, 0)
+				for _, runeValue := range word {
+					if states = stepPattern(pattern, states, runeValue); states == nil {
+						return true
+					}
+				}
+				if states[len(pattern)] {
+					found = append(found, idx.words.postingsOf(ordinal))
+				}
+				return true
+			})
+		}
+		postingList = append(postingList, unionPostingList(found))
+	}
+	return orderByRelevance(idx.documents, intersectPostingList(postingList))
+}
+
+// SearchRegexp return the IDs that have at least one word matched by the regexp
+// only the words that start with the literal prefix of an anchored regexp are matched
+func (idx *Index) SearchRegexp(re *regexp.Regexp) []SearchData {
+	var found []*postings
+	if r, ok := idx.words.lookup(regexpPrefix(re)); ok {
+		idx.words.automaton.each(r, func(word string, ordinal int) bool {
+			if re.MatchString(word) {
+				found = append(found, idx.words.postingsOf(ordinal))
+			}
+			return true
+		})
+	}
+	return orderByRelevance(idx.documents, unionPostingList(found))
+}
+
+// lookup returns the range of the words that start with the cleaned prefix, it is false if there is none
+func (s *indexSection) lookup(prefix string) (wordRange, bool) {
+	r := s.automaton.seek(prefix)
+	return r, r.prefix == prefix && r.last <= s.size
+}
+
+// union returns the documents of the words with the ordinals in the range
+func (s *indexSection) union(first, last int) *postings {
+	p, _ := s.unionContext(context.Background(), first, last)
+	return p
+}
+
+// unionContext returns the documents of the words with the ordinals in the range, it stops with ErrCanceled when the context is done
+func (s *indexSection) unionContext(ctx context.Context, first, last int) (*postings, error) {
+	var found []*postings
+	for ordinal := first; ordinal < last; ordinal++ {
+		if err := checkContext(ctx); err != nil {
+			return nil, err
+		}
+		found = append(found, s.postingsOf(ordinal))
+	}
+	return unionPostingList(found), nil
+}
+
+// relevantPostings returns the documents of the prefix in the same way as the nodes of the trie are intersected
+// the documents of the word when the prefix is a word and the ones of the longer words when it is not
+func (s *indexSection) relevantPostings(ctx context.Context, r wordRange) (*postings, error) {
+	switch {
+	case r.prefix == "" || r.last > s.size:
+		return nil, nil
+	case r.isWord:
+		return s.postingsOf(r.first), nil
+	}
+	return s.unionContext(ctx, r.first, r.last)
+}
+
+// term returns the term of the prefix found, the longer words are only read when they are visited
+func (s *indexSection) term(r wordRange) queryTerm {
+	return queryTerm{
+		word:  r.prefix,
+		exact: r.isWord,
+		each: func(visit func(words map[string]int, p *postings)) {
+			for ordinal := r.first; ordinal < r.last; ordinal++ {
+				visit(s.wordsOf(ordinal), s.postingsOf(ordinal))
+			}
+		},
+	}
+}
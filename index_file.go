@@ -0,0 +1,374 @@
+package trie
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/bits"
+	"sort"
+)
+
+// The index file starts with the magic and the offset and size of the sections of the documents, the words and the suffixes
+const (
+	indexMagic      = "TRIEIDX2"
+	indexHeaderSize = len(indexMagic) + 3*16
+	// every document has the ID, the name and the words in the table of documents
+	documentStrings = 3
+)
+
+// ErrInvalidIndex is returned when the file is not an index written by WriteIndex or it is damaged
+var ErrInvalidIndex = errors.New("trie: invalid index file")
+
+// indexDocuments is the table of IDs, names and words of the index file, the strings are only copied when a document is returned
+type indexDocuments struct {
+	size    int
+	offsets []byte
+	strings []byte
+}
+
+// indexSection is the automaton of the words of a trie and the record of every word, with its original words and postings
+// the records are in the same order of the ordinals of the automaton
+type indexSection struct {
+	automaton automaton
+	size      int
+	offsets   []byte
+	records   []byte
+}
+
+// WriteIndex writes the trie as an immutable index that can be opened by OpenIndex
+// the words are kept in a minimized automaton, so the words with the same suffix share the same states,
+// and the original words and postings of every word are written after it
+func (t *Node) WriteIndex(w io.Writer) error {
+	documents := encodeDocuments(t.documents)
+	words := encodeSection(t)
+	suffixes := encodeSection(t.suffixes)
+	header := make([]byte, indexHeaderSize)
+	copy(header, indexMagic)
+	offset := uint64(indexHeaderSize)
+	for i, section := range [][]byte{documents, words, suffixes} {
+		binary.LittleEndian.PutUint64(header[len(indexMagic)+i*16:], offset)
+		binary.LittleEndian.PutUint64(header[len(indexMagic)+i*16+8:], uint64(len(section)))
+		offset += uint64(len(section))
+	}
+	for _, data := range [][]byte{header, documents, words, suffixes} {
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OpenIndex maps the index file in memory and returns the index ready to be searched
+// nothing is decoded when the file is opened, the index must be closed when it is not used anymore
+func OpenIndex(path string) (*Index, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	index, err := LoadIndex(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	index.unmap = unmap
+	return index, nil
+}
+
+// LoadIndex returns the index of the data written by WriteIndex, the data is used directly and must not be changed
+func LoadIndex(data []byte) (*Index, error) {
+	if len(data) < indexHeaderSize || string(data[:len(indexMagic)]) != indexMagic {
+		return nil, ErrInvalidIndex
+	}
+	var sections [3][]byte
+	for i := range sections {
+		offset := binary.LittleEndian.Uint64(data[len(indexMagic)+i*16:])
+		size := binary.LittleEndian.Uint64(data[len(indexMagic)+i*16+8:])
+		if offset > uint64(len(data)) || size > uint64(len(data))-offset {
+			return nil, ErrInvalidIndex
+		}
+		sections[i] = data[offset : offset+size]
+	}
+	index := &Index{}
+	var ok bool
+	if index.documents, ok = decodeDocuments(sections[0]); !ok {
+		return nil, ErrInvalidIndex
+	}
+	if index.words, ok = decodeSection(sections[1]); !ok {
+		return nil, ErrInvalidIndex
+	}
+	if index.suffixes, ok = decodeSection(sections[2]); !ok {
+		return nil, ErrInvalidIndex
+	}
+	return index, nil
+}
+
+// Close releases the memory of the file, the index must not be used after it is closed
+func (idx *Index) Close() error {
+	if idx.unmap == nil {
+		return nil
+	}
+	err := idx.unmap()
+	idx.unmap = nil
+	return err
+}
+
+// encodeDocuments writes the number of documents, the offsets of the IDs, names and words and then the strings
+// the words are only read when the index is read again as a trie, so its documents are removed with the words they were added with
+func encodeDocuments(d *documentTable) []byte {
+	var ids, names, words []string
+	if d != nil {
+		ids, names, words = d.ids, d.names, d.words
+	}
+	data := make([]byte, 4+(documentStrings*len(ids)+1)*8)
+	binary.LittleEndian.PutUint32(data, uint32(len(ids)))
+	size := 0
+	for i := range ids {
+		for j, value := range []string{ids[i], names[i], words[i]} {
+			binary.LittleEndian.PutUint64(data[4+(documentStrings*i+j)*8:], uint64(size))
+			size += len(value)
+		}
+	}
+	binary.LittleEndian.PutUint64(data[4+documentStrings*len(ids)*8:], uint64(size))
+	for i := range ids {
+		data = append(append(append(data, ids[i]...), names[i]...), words[i]...)
+	}
+	return data
+}
+
+func decodeDocuments(data []byte) (indexDocuments, bool) {
+	if len(data) < 4 {
+		return indexDocuments{}, false
+	}
+	size := int(binary.LittleEndian.Uint32(data))
+	if uint64(len(data)-4)/8 < documentStrings*uint64(size)+1 {
+		return indexDocuments{}, false
+	}
+	end := 4 + (documentStrings*size+1)*8
+	return indexDocuments{size: size, offsets: data[4:end], strings: data[end:]}, true
+}
+
+// document returns the ID and the name of the document ordinal, they are copied from the file
+func (d indexDocuments) document(doc uint32) (string, string) {
+	if int(doc) >= d.size {
+		return "", ""
+	}
+	return d.text(documentStrings * int(doc)), d.text(documentStrings*int(doc) + 1)
+}
+
+// words returns the original words inserted for the document ordinal separated by spaces
+func (d indexDocuments) words(doc uint32) string {
+	if int(doc) >= d.size {
+		return ""
+	}
+	return d.text(documentStrings*int(doc) + 2)
+}
+
+// ordinal returns the ordinal of the ID, every ID of the file is compared until it is found
+func (d indexDocuments) ordinal(id string) (uint32, bool) {
+	if id == "" {
+		return 0, false
+	}
+	for doc := 0; doc < d.size; doc++ {
+		if d.text(documentStrings*doc) == id {
+			return uint32(doc), true
+		}
+	}
+	return 0, false
+}
+
+func (d indexDocuments) text(i int) string {
+	start, end := binary.LittleEndian.Uint64(d.offsets[i*8:]), binary.LittleEndian.Uint64(d.offsets[(i+1)*8:])
+	if start > end || end > uint64(len(d.strings)) {
+		return ""
+	}
+	return string(d.strings[start:end])
+}
+
+// encodeSection writes the number of words, the root and the size of the automaton, the automaton, the offsets of the records and the records
+// every word of the trie is inserted in the automaton in increasing order
+func encodeSection(root *Node) []byte {
+	var nodes []*Node
+	if root != nil {
+		root.walkWords(func(node *Node) {
+			nodes = append(nodes, node)
+		})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].currentWord < nodes[j].currentWord
+	})
+	builder := newAutomatonBuilder()
+	offsets := make([]byte, (len(nodes)+1)*8)
+	var records []byte
+	for i, node := range nodes {
+		builder.insert(node.currentWord)
+		binary.LittleEndian.PutUint64(offsets[i*8:], uint64(len(records)))
+		records = appendRecord(records, node.correctWords, &node.correctData)
+	}
+	binary.LittleEndian.PutUint64(offsets[len(nodes)*8:], uint64(len(records)))
+	states, rootState := builder.bytes()
+	data := make([]byte, 16, 16+len(states)+len(offsets)+len(records))
+	binary.LittleEndian.PutUint32(data, uint32(len(nodes)))
+	binary.LittleEndian.PutUint32(data[4:], rootState)
+	binary.LittleEndian.PutUint64(data[8:], uint64(len(states)))
+	return append(append(append(data, states...), offsets...), records...)
+}
+
+func decodeSection(data []byte) (indexSection, bool) {
+	if len(data) < 16 {
+		return indexSection{}, false
+	}
+	size := binary.LittleEndian.Uint32(data)
+	states := binary.LittleEndian.Uint64(data[8:])
+	rest := data[16:]
+	if states > uint64(len(rest)) || uint64(len(rest))-states < (uint64(size)+1)*8 {
+		return indexSection{}, false
+	}
+	section := indexSection{
+		automaton: automaton{data: rest[:states], root: binary.LittleEndian.Uint32(data[4:])},
+		size:      int(size),
+		offsets:   rest[states : states+(uint64(size)+1)*8],
+		records:   rest[states+(uint64(size)+1)*8:],
+	}
+	return section, section.automaton.valid(section.automaton.root)
+}
+
+// appendRecord writes the original words with their counts, the containers of the bitmap and the positions of every document
+func appendRecord(data []byte, words map[string]int, p *postings) []byte {
+	keys := getKeyListFromMap(words)
+	sort.Strings(keys)
+	data = appendUvarint(data, len(keys))
+	for _, word := range keys {
+		data = append(appendUvarint(data, len(word)), word...)
+		data = appendUvarint(data, words[word])
+	}
+	data = appendUvarint(data, len(p.docs.keys))
+	for i, c := range p.docs.containers {
+		data = appendUvarint(appendUvarint(data, int(p.docs.keys[i])), c.size)
+		if c.size > maxArraySize {
+			for _, word := range c.bitset {
+				data = append(data, byte(word), byte(word>>8), byte(word>>16), byte(word>>24), byte(word>>32), byte(word>>40), byte(word>>48), byte(word>>56))
+			}
+			continue
+		}
+		c.each(func(low uint16) bool {
+			data = append(data, byte(low), byte(low>>8))
+			return true
+		})
+	}
+	for _, position := range p.positions {
+		data = appendUvarint(data, len(position))
+		for _, value := range position {
+			data = appendUvarint(data, value)
+		}
+	}
+	return data
+}
+
+func appendUvarint(data []byte, value int) []byte {
+	var buffer [binary.MaxVarintLen64]byte
+	return append(data, buffer[:binary.PutUvarint(buffer[:], uint64(value))]...)
+}
+
+// record returns the decoder of the record of the word ordinal
+func (s *indexSection) record(ordinal int) *decoder {
+	if ordinal < 0 || ordinal >= s.size {
+		return &decoder{failed: true}
+	}
+	start, end := binary.LittleEndian.Uint64(s.offsets[ordinal*8:]), binary.LittleEndian.Uint64(s.offsets[(ordinal+1)*8:])
+	if start > end || end > uint64(len(s.records)) {
+		return &decoder{failed: true}
+	}
+	return &decoder{data: s.records[start:end]}
+}
+
+// wordsOf returns the original words of the word ordinal and how many times each one was inserted
+func (s *indexSection) wordsOf(ordinal int) map[string]int {
+	d := s.record(ordinal)
+	words := make(map[string]int)
+	for n := d.uvarint(); n > 0 && !d.failed; n-- {
+		word := string(d.bytes(d.uvarint()))
+		words[word] = d.uvarint()
+	}
+	return words
+}
+
+// postingsOf returns the documents of the word ordinal, an empty postings is returned if the record is damaged
+func (s *indexSection) postingsOf(ordinal int) *postings {
+	d := s.record(ordinal)
+	for n := d.uvarint(); n > 0 && !d.failed; n-- {
+		d.bytes(d.uvarint())
+		d.uvarint()
+	}
+	p := &postings{}
+	for n := d.uvarint(); n > 0 && !d.failed; n-- {
+		key, size := d.uvarint(), d.uvarint()
+		c := container{size: size}
+		if size > maxArraySize {
+			words := d.bytes(bitsetSize * 8)
+			if d.failed {
+				break
+			}
+			c.bitset = make([]uint64, bitsetSize)
+			c.size = 0
+			for i := range c.bitset {
+				c.bitset[i] = binary.LittleEndian.Uint64(words[i*8:])
+				c.size += bits.OnesCount64(c.bitset[i])
+			}
+		} else {
+			values := d.bytes(size * 2)
+			if d.failed {
+				break
+			}
+			c.array = make([]uint16, size)
+			for i := range c.array {
+				c.array[i] = binary.LittleEndian.Uint16(values[i*2:])
+			}
+		}
+		p.docs.keys = append(p.docs.keys, uint16(key))
+		p.docs.containers = append(p.docs.containers, c)
+	}
+	p.positions = make([][]int, p.len())
+	for i := range p.positions {
+		n := d.uvarint()
+		if n > len(d.data) {
+			d.failed = true
+		}
+		if d.failed {
+			return &postings{}
+		}
+		p.positions[i] = make([]int, n)
+		for j := range p.positions[i] {
+			p.positions[i][j] = d.uvarint()
+		}
+	}
+	if d.failed {
+		return &postings{}
+	}
+	return p
+}
+
+// decoder reads the values of a record or a cursor, after the data ends every value is zero and failed is true
+type decoder struct {
+	data   []byte
+	failed bool
+}
+
+func (d *decoder) uvarint() int {
+	value, n := binary.Uvarint(d.data)
+	if n <= 0 || int(value) < 0 {
+		d.failed = true
+		return 0
+	}
+	d.data = d.data[n:]
+	return int(value)
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.failed || n < 0 || n > len(d.data) {
+		d.failed = true
+		return nil
+	}
+	value := d.data[:n]
+	d.data = d.data[n:]
+	return value
+}
@@ -0,0 +1,233 @@
+package trie
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// indexDocs are the documents of indexTrie in the order they are added
+var indexDocs = []Document{
+	{"1", "Direito Penal"}, {"2", "Direito Civil"}, {"3", "Direção Defensiva"}, {"4", "Administração Pública"},
+	{"5", "Direito Administrativo"}, {"6", "Dir"}, {"7", "Direito Penal Militar"},
+}
+
+func indexTrie() *Node {
+	return addDocuments(indexDocs)
+}
+
+// addedAgainTrie returns the trie of indexDocs with a document added again with a word before the one of its first name
+func addedAgainTrie() *Node {
+	trieNode := addDocuments(append(indexDocs[:len(indexDocs):len(indexDocs)], Document{"8", "Penal Direito"}, Document{"9", "Civil Direito"}))
+	trieNode.Add("8", "Direito")
+	return trieNode
+}
+
+func writeIndexFile(t *testing.T, trieNode *Node) string {
+	path := filepath.Join(t.TempDir(), "trie.idx")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := trieNode.WriteIndex(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// searcherCases call every method of the SearcherInterface, two searchers with the same data return the same values
+var searcherCases = map[string]func(s SearcherInterface) interface{}{
+	"Is filled":                      func(s SearcherInterface) interface{} { return s.IsFilled() },
+	"Has word":                       func(s SearcherInterface) interface{} { return s.HasWord("Direito") },
+	"Has not word":                   func(s SearcherInterface) interface{} { return s.HasWord("Dire") },
+	"Possible words":                 func(s SearcherInterface) interface{} { return s.GetPossibleWords("dire") },
+	"Possible words of root":         func(s SearcherInterface) interface{} { return s.GetPossibleWords("") },
+	"Complete":                       func(s SearcherInterface) interface{} { return s.Complete("dir", 3) },
+	"Complete with big k":            func(s SearcherInterface) interface{} { return s.Complete("d", 20) },
+	"Complete not found":             func(s SearcherInterface) interface{} { return s.Complete("xyz", 3) },
+	"Autocomplete phrase":            func(s SearcherInterface) interface{} { return s.AutocompletePhrase("direito p", 5) },
+	"Autocomplete phrase not a word": func(s SearcherInterface) interface{} { return s.AutocompletePhrase("dire p", 5) },
+	"Search word":                    func(s SearcherInterface) interface{} { return s.SearchByRelevance("direito") },
+	"Search prefix":                  func(s SearcherInterface) interface{} { return s.SearchByRelevance("dire") },
+	"Search inside of an edge":       func(s SearcherInterface) interface{} { return s.SearchByRelevance("direit") },
+	"Search longest prefix":          func(s SearcherInterface) interface{} { return s.SearchByRelevance("direitos") },
+	"Search phrase":                  func(s SearcherInterface) interface{} { return s.SearchByRelevance("penal direito") },
+	"Search word not found":          func(s SearcherInterface) interface{} { return s.SearchByRelevance("xyz penal") },
+	"Search paginated": func(s SearcherInterface) interface{} {
+		data, pagination := s.SearchByRelevancePaginated("dir", Pagination{PerPage: 2, Page: 2})
+		return []interface{}{data, pagination}
+	},
+	"Iterate search":    func(s SearcherInterface) interface{} { return collect(s.IterSearch("dir"), 3) },
+	"Explain":           func(s SearcherInterface) interface{} { return s.Explain("penal dire", "7") },
+	"Explain not found": func(s SearcherInterface) interface{} { return s.Explain("xyz penal", "2") },
+	"Walk prefix": func(s SearcherInterface) interface{} {
+		var words []string
+		s.WalkPrefix("di", func(word string, ids []string) bool {
+			words = append(words, fmt.Sprint(word, " ", ids))
+			return true
+		})
+		return words
+	},
+	"Search with cursor": func(s SearcherInterface) interface{} {
+		results, _ := readAllPages(s, "dir", 2)
+		return results
+	},
+	"Search with context": func(s SearcherInterface) interface{} {
+		result, err := s.SearchContext(context.Background(), "dire", SearchOptions{Limit: 3, Facets: 2})
+		result.Took = 0
+		return []interface{}{result, err}
+	},
+	"Search highlighted":               func(s SearcherInterface) interface{} { return s.SearchByRelevanceHighlighted("direito pen") },
+	"Search infix":                     func(s SearcherInterface) interface{} { return s.SearchInfix("acao reit") },
+	"Search pattern":                   func(s SearcherInterface) interface{} { return s.SearchPattern("dire*o p?nal") },
+	"Search pattern starting wildcard": func(s SearcherInterface) interface{} { return s.SearchPattern("*acao") },
+	"Search regexp":                    func(s SearcherInterface) interface{} { return s.SearchRegexp(regexp.MustCompile("^dire(i|c)")) },
+	"Search regexp not anchored":       func(s SearcherInterface) interface{} { return s.SearchRegexp(regexp.MustCompile("nal$")) },
+}
+
+func Test_IndexSearch(t *testing.T) {
+	for trieName, build := range map[string]func() *Node{"Trie": indexTrie, "Added again": addedAgainTrie} {
+		index, err := OpenIndex(writeIndexFile(t, build()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer index.Close()
+
+		for name, search := range searcherCases {
+			t.Run(trieName+"/"+name, func(t *testing.T) {
+				diff := cmp.Diff(search(build()), search(index))
+				if diff != "" {
+					t.Fatalf(diff)
+				}
+			})
+		}
+	}
+}
+
+func Test_IndexSameAsTrie(t *testing.T) {
+	trieNode := syntheticTrie(10000)
+	var buffer bytes.Buffer
+	if err := trieNode.WriteIndex(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	index, err := LoadIndex(buffer.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, first := range syllables {
+		for _, second := range []string{"", "ba", "lo", "ri"} {
+			prefix := first + second
+			if len(prefix) < minWordSize {
+				continue
+			}
+			diff := cmp.Diff(trieNode.SearchByRelevance(prefix), index.SearchByRelevance(prefix))
+			if diff != "" {
+				t.Fatalf("%v: %v", prefix, diff)
+			}
+			diff = cmp.Diff(trieNode.Complete(prefix, 5), index.Complete(prefix, 5))
+			if diff != "" {
+				t.Fatalf("%v: %v", prefix, diff)
+			}
+			diff = cmp.Diff(trieNode.SearchInfix(prefix), index.SearchInfix(prefix))
+			if diff != "" {
+				t.Fatalf("%v: %v", prefix, diff)
+			}
+			diff = cmp.Diff(trieNode.GetCorrectIDs(prefix), index.GetCorrectIDs(prefix), cmpopts.EquateEmpty())
+			if diff != "" {
+				t.Fatalf("%v: %v", prefix, diff)
+			}
+			expected, got := trieNode.GetPossibleWords(prefix), index.GetPossibleWords(prefix)
+			sort.Strings(expected)
+			sort.Strings(got)
+			diff = cmp.Diff(expected, got)
+			if diff != "" {
+				t.Fatalf("%v: %v", prefix, diff)
+			}
+		}
+	}
+}
+
+func Test_LoadInvalidIndex(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := indexTrie().WriteIndex(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+
+	cases := map[string]struct {
+		data []byte
+	}{
+		"Empty file":        {nil},
+		"Wrong magic":       {append([]byte("NOTANIDX"), data[len(indexMagic):]...)},
+		"Truncated file":    {data[:len(data)/2]},
+		"Truncated header":  {data[:indexHeaderSize-1]},
+		"Sections too long": {append(append([]byte{}, data[:len(indexMagic)]...), bytes.Repeat([]byte{0xff}, len(data)-len(indexMagic))...)},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadIndex(tc.data); err != ErrInvalidIndex {
+				t.Fatalf("\nExpected: %v\nGot: %v", ErrInvalidIndex, err)
+			}
+		})
+	}
+}
+
+func Test_DamagedIndexRecords(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := indexTrie().WriteIndex(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+	index, err := LoadIndex(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range index.words.records {
+		index.words.records[i] = 0xff
+	}
+	if got := index.SearchByRelevance("direito"); got != nil {
+		t.Fatalf("\nExpected: %v\nGot: %v", nil, got)
+	}
+	if got := index.Complete("dir", 3); got != nil {
+		t.Fatalf("\nExpected: %v\nGot: %v", nil, got)
+	}
+}
+
+func Benchmark_OpenIndex(b *testing.B) {
+	for _, words := range []int{10000, 100000} {
+		b.Run(fmt.Sprintf("words=%d", words), func(b *testing.B) {
+			path := filepath.Join(b.TempDir(), "trie.idx")
+			file, err := os.Create(path)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := syntheticTrie(words).WriteIndex(file); err != nil {
+				b.Fatal(err)
+			}
+			info, _ := file.Stat()
+			file.Close()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				index, err := OpenIndex(path)
+				if err != nil {
+					b.Fatal(err)
+				}
+				index.SearchByRelevance("bako")
+				index.Close()
+			}
+			b.ReportMetric(float64(info.Size()), "file-bytes")
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package trie
+
+import "strings"
+
+// insertSuffixes will insert every suffix of the cleaned word in the suffix trie of the root node
+// the complete word is already in the main trie, so only the suffixes starting after the first rune are inserted
+func (t *Node) insertSuffixes(doc uint32, word, cleanedString string, position int) {
+	if t.suffixes == nil {
+		t.suffixes = &Node{children: make(map[rune]*Node)}
+	}
+	eachSuffix(cleanedString, func(suffix string) {
+		t.suffixes.insert(doc, word, suffix, position)
+	})
+}
+
+// eachSuffix visits the suffixes of the cleaned word that start after the first rune and have at least the min word size
+func eachSuffix(cleanedString string, visit func(suffix string)) {
+	for i := range cleanedString {
+		if i == 0 {
+			continue
+		}
+		if len(cleanedString[i:]) < minWordSize {
+			return
+		}
+		visit(cleanedString[i:])
+	}
+}
+
+// SearchInfix return the matching IDs for the fragments in the phrase, a fragment may be found in the beginning, middle or end of a word
+// The result is ordered in the same way as SearchByRelevance
+func (t *Node) SearchInfix(phrase string) []SearchData {
+	return orderByRelevance(t.documents, t.searchInfixPostings(phrase))
+}
+
+// searchInfixPostings returns the documents that have a word containing every fragment of the phrase
+func (t *Node) searchInfixPostings(phrase string) *postings {
+	var postingList []*postings
+	for _, fragment := range strings.Fields(phrase) {
+		cleanedString := cleanString(fragment)
+		if len(cleanedString) < minWordSize {
+			continue
+		}
+		postingList = append(postingList, t.infixPostings(cleanedString))
+	}
+	return intersectPostingList(postingList)
+}
+
+// infixPostings returns every document that has a word containing the fragment
+// the documents are found in the main trie when the fragment is a prefix and in the suffix trie otherwise
+func (t *Node) infixPostings(fragment string) *postings {
+	var found []*postings
+	roots := []*Node{t}
+	if t.suffixes != nil {
+		roots = append(roots, t.suffixes)
+	}
+	for _, root := range roots {
+		node := root.getNode(fragment)
+		if node == nil {
+			continue
+		}
+		found = append(found, &node.correctData, &node.possibleData)
+	}
+	return unionPostingList(found)
+}
@@ -0,0 +1,37 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_SearchInfix(t *testing.T) {
+	trieNode := NewNode()
+	trieNode.Add("1", "Administração Pública")
+	trieNode.Add("2", "Direito Administrativo")
+	trieNode.Add("3", "Direito Penal")
+	trieNode.Add("4", "Tributação")
+
+	cases := map[string]struct {
+		phrase   string
+		expected []SearchData
+	}{
+		"Suffix of a word":                   {"acao", []SearchData{{"4", "Tributação"}, {"1", "Administração Pública"}}},
+		"Middle of a word":                   {"nistra", []SearchData{{"1", "Administração Pública"}, {"2", "Direito Administrativo"}}},
+		"Prefix of a word":                   {"direit", []SearchData{{"3", "Direito Penal"}, {"2", "Direito Administrativo"}}},
+		"Complete word":                      {"penal", []SearchData{{"3", "Direito Penal"}}},
+		"Multiple fragments":                 {"reit trativo", []SearchData{{"2", "Direito Administrativo"}}},
+		"Fragment not found":                 {"xyz", nil},
+		"Fragment smaller than minimum size": {"ao", nil},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expected, trieNode.SearchInfix(tc.phrase))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package trie
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// NodeInfo return the data of the node of the word, it returns false if no word passes by it
+func (t *Node) NodeInfo(word string) (NodeInfo, bool) {
+	cleanedString := cleanString(word)
+	node := t.getNode(cleanedString)
+	if node == nil || node == t {
+		return NodeInfo{}, false
+	}
+	return node.info(cleanedString), true
+}
+
+// PathTo return the data of the node of every prefix of the word, from its first rune until the word
+func (t *Node) PathTo(word string) []NodeInfo {
+	var path []NodeInfo
+	cleanedString := cleanString(word)
+	for i, runeValue := range cleanedString {
+		prefix := cleanedString[:i+utf8.RuneLen(runeValue)]
+		node := t.getNode(prefix)
+		if node == nil {
+			break
+		}
+		path = append(path, node.info(prefix))
+	}
+	return path
+}
+
+func (t *Node) info(prefix string) NodeInfo {
+	return NodeInfo{
+		Prefix:        prefix,
+		IsWord:        t.isWord,
+		CorrectIDs:    t.correctData.len(),
+		PossibleIDs:   t.possibleData.len(),
+		CorrectWords:  len(t.correctWords),
+		PossibleWords: len(t.possibleWords),
+	}
+}
+
+// String return the prefix and the counts of the node in a single line
+func (i NodeInfo) String() string {
+	return fmt.Sprintf("%s word=%t correct=%d/%d possible=%d/%d", i.Prefix, i.IsWord, i.CorrectIDs, i.CorrectWords, i.PossibleIDs, i.PossibleWords)
+}
+
+// WritePath writes every node of the path in a line, it replaces the printing of the path to a word
+func WritePath(w io.Writer, path []NodeInfo) error {
+	for _, info := range path {
+		if _, err := fmt.Fprintln(w, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func introspectTrie() *Node {
+	trie := NewNode()
+	trie.Add("1", "Direito Penal")
+	trie.Add("2", "Direito Civil")
+	trie.Add("3", "Direção Defensiva")
+	return trie
+}
+
+func Test_NodeInfo(t *testing.T) {
+	cases := map[string]struct {
+		word     string
+		expected NodeInfo
+		ok       bool
+	}{
+		"Word":           {"Direito", NodeInfo{Prefix: "direito", IsWord: true, CorrectIDs: 2, CorrectWords: 1}, true},
+		"Split prefix":   {"dire", NodeInfo{Prefix: "dire", PossibleIDs: 3, PossibleWords: 2}, true},
+		"Inside an edge": {"pen", NodeInfo{Prefix: "pen", PossibleIDs: 1, PossibleWords: 1}, true},
+		"Accents":        {"direção", NodeInfo{Prefix: "direcao", IsWord: true, CorrectIDs: 1, CorrectWords: 1}, true},
+		"Missing word":   {"direitos", NodeInfo{}, false},
+		"Empty word":     {"", NodeInfo{}, false},
+	}
+
+	trie := introspectTrie()
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			info, ok := trie.NodeInfo(tc.word)
+			if ok != tc.ok {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.ok, ok)
+			}
+			diff := cmp.Diff(tc.expected, info)
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_PathTo(t *testing.T) {
+	cases := map[string]struct {
+		word     string
+		expected []NodeInfo
+	}{
+		"Word": {"civil", []NodeInfo{
+			{Prefix: "c", PossibleIDs: 1, PossibleWords: 1},
+			{Prefix: "ci", PossibleIDs: 1, PossibleWords: 1},
+			{Prefix: "civ", PossibleIDs: 1, PossibleWords: 1},
+			{Prefix: "civi", PossibleIDs: 1, PossibleWords: 1},
+			{Prefix: "civil", IsWord: true, CorrectIDs: 1, CorrectWords: 1},
+		}},
+		"Stops at the missing prefix": {"penha", []NodeInfo{
+			{Prefix: "p", PossibleIDs: 1, PossibleWords: 1},
+			{Prefix: "pe", PossibleIDs: 1, PossibleWords: 1},
+			{Prefix: "pen", PossibleIDs: 1, PossibleWords: 1},
+		}},
+		"Missing word": {"xyz", nil},
+	}
+
+	trie := introspectTrie()
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expected, trie.PathTo(tc.word))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_WritePath(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := WritePath(&buffer, introspectTrie().PathTo("dir")); err != nil {
+		t.Fatal(err)
+	}
+	expected := "" +
+		"d word=false correct=0/0 possible=3/3\n" +
+		"di word=false correct=0/0 possible=3/2\n" +
+		"dir word=false correct=0/0 possible=3/2\n"
+	diff := cmp.Diff(expected, buffer.String())
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
@@ -0,0 +1,85 @@
+package trie
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// IterSearch return an iterator over the same results of SearchByRelevance, in the same order
+// the results are ordered while they are read, so a caller that stops early does not pay for ordering every result
+func (t *Node) IterSearch(phrase string) Iterator {
+	return newRelevanceIterator(t.documents, t.searchPostings(phrase))
+}
+
+// WalkPrefix visits the words that start with the prefix in increasing order with the IDs of the documents of every word
+// the word is the form inserted more times, the walk stops when the visit returns false
+func (t *Node) WalkPrefix(prefix string, visit func(word string, ids []string) bool) {
+	node := t.getNode(cleanString(prefix))
+	if node == nil {
+		return
+	}
+	node.walkSortedWords(func(word *Node) bool {
+		return visit(mostInserted(word.correctWords), idList(t.documents, &word.correctData))
+	})
+}
+
+// walkSortedWords visits the node and every node under it that is the end of a word in increasing order, until the visit returns false
+func (t *Node) walkSortedWords(visit func(node *Node) bool) bool {
+	if t.isWord && !visit(t) {
+		return false
+	}
+	keys := make([]rune, 0, len(t.children))
+	for key := range t.children {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+	for _, key := range keys {
+		if !t.children[key].walkSortedWords(visit) {
+			return false
+		}
+	}
+	return true
+}
+
+// relevanceIterator returns the documents of the postings in the order of SearchByRelevance
+// the documents are kept in a heap, so only the documents that are read are ordered
+type relevanceIterator struct {
+	values byRelevance
+	value  SearchData
+}
+
+func newRelevanceIterator(source documentSource, p *postings) *relevanceIterator {
+	it := &relevanceIterator{values: relevanceValues(source, p)}
+	heap.Init(&it.values)
+	return it
+}
+
+// Next moves to the next result, it returns false when there are no more results
+func (it *relevanceIterator) Next() bool {
+	if len(it.values) == 0 {
+		return false
+	}
+	value := heap.Pop(&it.values).(*internalOrderData)
+	it.value = SearchData{ID: value.id, Name: value.name}
+	return true
+}
+
+// Value returns the current result
+func (it *relevanceIterator) Value() SearchData {
+	return it.value
+}
+
+// Err returns the error that stopped the iteration, a search in memory does not fail
+func (it *relevanceIterator) Err() error {
+	return nil
+}
+
+func (n *byRelevance) Push(x interface{}) { *n = append(*n, x.(*internalOrderData)) }
+func (n *byRelevance) Pop() interface{} {
+	old := *n
+	last := old[len(old)-1]
+	*n = old[:len(old)-1]
+	return last
+}
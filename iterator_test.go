@@ -0,0 +1,92 @@
+package trie
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func collect(it Iterator, limit int) []SearchData {
+	var results []SearchData
+	for len(results) < limit && it.Next() {
+		results = append(results, it.Value())
+	}
+	return results
+}
+
+func Test_IterSearch(t *testing.T) {
+	cases := map[string]struct {
+		phrase string
+		limit  int
+	}{
+		"Every result":     {"dir", 10},
+		"Stop early":       {"dir", 2},
+		"Phrase":           {"direito penal", 10},
+		"Nothing found":    {"xyz", 10},
+		"Only short words": {"di", 10},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			expected := indexTrie().SearchByRelevance(tc.phrase)
+			if len(expected) > tc.limit {
+				expected = expected[:tc.limit]
+			}
+			it := indexTrie().IterSearch(tc.phrase)
+			diff := cmp.Diff(expected, collect(it, tc.limit))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			if it.Err() != nil {
+				t.Fatalf("\nExpected: %v\nGot: %v", nil, it.Err())
+			}
+		})
+	}
+}
+
+func Test_WalkPrefix(t *testing.T) {
+	trieNode := indexTrie()
+	trieNode.Add("8", "direito DIREITO")
+
+	cases := map[string]struct {
+		prefix   string
+		limit    int
+		expected []string
+	}{
+		"Prefix of words":       {"dire", 10, []string{"Direção [3]", "Direito [1 2 5 7 8]"}},
+		"Prefix is a word":      {"dir", 10, []string{"Dir [6]", "Direção [3]", "Direito [1 2 5 7 8]"}},
+		"Inside of an edge":     {"direi", 10, []string{"Direito [1 2 5 7 8]"}},
+		"Stop early":            {"d", 2, []string{"Defensiva [3]", "Dir [6]"}},
+		"Prefix not found":      {"xyz", 10, nil},
+		"Accents in the prefix": {"Direç", 10, []string{"Direção [3]"}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			trieNode.WalkPrefix(tc.prefix, func(word string, ids []string) bool {
+				got = append(got, fmt.Sprint(word, " ", ids))
+				return len(got) < tc.limit
+			})
+			diff := cmp.Diff(tc.expected, got)
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Benchmark_IterSearch(b *testing.B) {
+	trieNode := syntheticTrie(100000)
+	b.Run("slice", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = trieNode.SearchByRelevance("bako")[:10]
+		}
+	})
+	b.Run("iterator", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			collect(trieNode.IterSearch("bako"), 10)
+		}
+	})
+}
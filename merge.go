@@ -0,0 +1,138 @@
+package trie
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// Merge adds the documents of the other trie, the words, their counts and the postings of every node of the other trie
+// are added to the same nodes of this trie, and the suffixes in the same way
+// a document of the other trie gets a new ordinal in this trie, an ID in both tries keeps its name and the positions of both,
+// so the trie is the same as if the documents of the other trie were added again with Add
+// The other trie is not changed, and it must not be this trie
+func (t *Node) Merge(other *Node) {
+	ordinals := t.documents.merge(other.documents)
+	other.walkWords(func(word *Node) {
+		t.mergeWord(word.currentWord, word.correctWords, word.correctData.remap(ordinals))
+	})
+	if other.suffixes == nil {
+		return
+	}
+	if t.suffixes == nil {
+		t.suffixes = &Node{children: make(map[rune]*Node)}
+	}
+	other.suffixes.walkWords(func(word *Node) {
+		t.suffixes.mergeWord(word.currentWord, word.correctWords, word.correctData.remap(ordinals))
+	})
+}
+
+// Subtract removes the documents with the IDs of the other trie
+// It returns the number of documents removed
+func (t *Node) Subtract(other *Node) int {
+	removed := 0
+	for _, id := range other.documents.ids {
+		if _, ok := other.documents.ordinals[id]; ok && t.Remove(id) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// merge creates the ordinals of the documents of the other table and returns them by the ordinal of the other table
+// the removed documents of the other table are not in any postings, so they are not added
+func (d *documentTable) merge(other *documentTable) []uint32 {
+	ordinals := make([]uint32, len(other.ids))
+	for doc, id := range other.ids {
+		if ordinal, ok := other.ordinals[id]; ok && ordinal == uint32(doc) {
+			ordinals[doc] = d.ordinal(id, other.names[doc])
+			d.addWords(ordinals[doc], other.words[doc])
+		}
+	}
+	return ordinals
+}
+
+// mergeWord adds the words and the postings of the cleaned word, in the same way insert adds a single word
+// the postings are added to the possible data of every node on the way and to the correct data of the node of the word
+func (t *Node) mergeWord(cleanedString string, words map[string]int, p *postings) {
+	node := t
+	for {
+		rest := cleanedString[len(node.currentWord):]
+		if node != t {
+			if len(rest) == 0 {
+				node.isWord = true
+				if node.correctWords == nil {
+					node.correctWords = make(map[string]int)
+				}
+				node.correctData.merge(p)
+				node.mergeWords(node.correctWords, words)
+				return
+			}
+			if node.possibleWords == nil {
+				node.possibleWords = make(map[string]int)
+			}
+			node.possibleData.merge(p)
+			node.mergeWords(node.possibleWords, words)
+		}
+		runeValue, _ := utf8.DecodeRuneInString(rest)
+		child, ok := node.children[runeValue]
+		if !ok {
+			child = &Node{currentWord: cleanedString}
+		} else if size := commonPrefixLength(child.label(node), rest); size < len(child.label(node)) {
+			child = splitNode(child, cleanedString[:len(node.currentWord)+size])
+		}
+		if node.children == nil {
+			node.children = make(map[rune]*Node)
+		}
+		node.children[runeValue] = child
+		node = child
+	}
+}
+
+// mergeWords adds the counts of the words to the words of the node, the counts only increase so the best completions are updated
+func (t *Node) mergeWords(into, words map[string]int) {
+	for word, count := range words {
+		into[word] += count
+		t.updateTopWords(word, into[word])
+	}
+}
+
+// remap returns the postings with the documents renamed to the ordinals, the positions are shared with the postings
+func (p *postings) remap(ordinals []uint32) *postings {
+	type entry struct {
+		doc      uint32
+		position []int
+	}
+	all := make([]entry, 0, p.len())
+	p.docs.each(func(doc uint32) bool {
+		all = append(all, entry{doc: ordinals[doc], position: p.positions[len(all)]})
+		return true
+	})
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].doc < all[j].doc
+	})
+	remapped := &postings{positions: make([][]int, 0, len(all))}
+	for _, e := range all {
+		remapped.docs.add(e.doc)
+		remapped.positions = append(remapped.positions, e.position)
+	}
+	return remapped
+}
+
+// merge adds the documents of the other postings, the positions of a document in both are merged as in add
+// the positions are copied, so the postings do not share them with the other postings
+func (p *postings) merge(other *postings) {
+	k := 0
+	other.docs.each(func(doc uint32) bool {
+		position := other.positions[k]
+		k++
+		if !p.docs.contains(doc) {
+			p.add(doc, position[0])
+			i := p.docs.rank(doc) - 1
+			p.positions[i] = append(p.positions[i], position[1:]...)
+			return true
+		}
+		i := p.docs.rank(doc) - 1
+		p.positions[i] = mergePositions(p.positions[i], position)
+		return true
+	})
+}
@@ -0,0 +1,175 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// addDocuments returns a trie with the documents added in order
+func addDocuments(docs []Document) *Node {
+	trieNode := NewNode()
+	for _, doc := range docs {
+		trieNode.Add(doc.ID, doc.Name)
+	}
+	return trieNode
+}
+
+func Test_Merge(t *testing.T) {
+	cases := []struct {
+		testName string
+		build    func() *Node
+		expected func() *Node
+	}{
+		{
+			testName: "Different documents",
+			build: func() *Node {
+				trieNode := addDocuments(indexDocs[:4])
+				trieNode.Merge(addDocuments(indexDocs[4:]))
+				return trieNode
+			},
+			expected: indexTrie,
+		},
+		{
+			testName: "Into an empty trie",
+			build: func() *Node {
+				trieNode := NewNode()
+				trieNode.Merge(indexTrie())
+				return trieNode
+			},
+			expected: indexTrie,
+		},
+		{
+			testName: "Empty trie",
+			build: func() *Node {
+				trieNode := indexTrie()
+				trieNode.Merge(NewNode())
+				return trieNode
+			},
+			expected: indexTrie,
+		},
+		{
+			testName: "Same ID in both",
+			build: func() *Node {
+				trieNode := addDocuments(indexDocs[:4])
+				trieNode.Merge(addDocuments([]Document{{"1", "Direito Penal Militar"}, {"8", "Direito Tributário"}}))
+				return trieNode
+			},
+			expected: func() *Node {
+				return addDocuments(append(indexDocs[:4:4], Document{"1", "Direito Penal Militar"}, Document{"8", "Direito Tributário"}))
+			},
+		},
+		{
+			testName: "Same ID with a word before the one of the first name",
+			build: func() *Node {
+				trieNode := addDocuments([]Document{{"1", "Direito Penal"}, {"2", "Código Penal"}})
+				trieNode.Merge(addDocuments([]Document{{"1", "Penal"}}))
+				return trieNode
+			},
+			expected: func() *Node {
+				return addDocuments([]Document{{"1", "Direito Penal"}, {"2", "Código Penal"}, {"1", "Penal"}})
+			},
+		},
+		{
+			testName: "Removed documents",
+			build: func() *Node {
+				trieNode := addDocuments(indexDocs[:4])
+				trieNode.Remove("2")
+				other := addDocuments(indexDocs[4:])
+				other.Add("8", "Direito Tributário")
+				other.Remove("6")
+				trieNode.Merge(other)
+				return trieNode
+			},
+			expected: func() *Node {
+				trieNode := indexTrie()
+				trieNode.Add("8", "Direito Tributário")
+				trieNode.Remove("2")
+				trieNode.Remove("6")
+				return trieNode
+			},
+		},
+		{
+			testName: "Changed after the merge",
+			build: func() *Node {
+				trieNode := addDocuments(indexDocs[:4])
+				trieNode.Merge(addDocuments(indexDocs[4:]))
+				trieNode.Remove("5")
+				trieNode.Add("8", "Direito Tributário")
+				return trieNode
+			},
+			expected: func() *Node {
+				trieNode := indexTrie()
+				trieNode.Remove("5")
+				trieNode.Add("8", "Direito Tributário")
+				return trieNode
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			diff := cmp.Diff(nodePrefixes(tc.expected()), nodePrefixes(tc.build()))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			diff = cmp.Diff(nodePrefixes(tc.expected().suffixes), nodePrefixes(tc.build().suffixes))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			for method, search := range searcherCases {
+				diff := cmp.Diff(search(tc.expected()), search(tc.build()))
+				if diff != "" {
+					t.Fatalf("%s: %s", method, diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_MergeSynthetic(t *testing.T) {
+	docs := syntheticDocuments(4000)
+	expected := addDocuments(docs)
+	merged := NewNode()
+	for _, part := range [][]Document{docs[:300], docs[300:700], docs[700:]} {
+		merged.Merge(addDocuments(part))
+	}
+	diff := cmp.Diff(trieContent(expected), trieContent(merged))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	diff = cmp.Diff(expected.Stats(), merged.Stats(), cmpopts.IgnoreFields(Stats{}, "HeapSize"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	for _, syllable := range syllables {
+		diff := cmp.Diff(expected.Complete(syllable, 5), merged.Complete(syllable, 5))
+		if diff != "" {
+			t.Fatalf("%v: %v", syllable, diff)
+		}
+	}
+}
+
+func Test_Subtract(t *testing.T) {
+	trieNode := indexTrie()
+	other := addDocuments([]Document{{"2", "Direito Civil"}, {"5", "Outro Nome"}, {"9", "Direito Tributário"}, {"4", "Administração Pública"}})
+	other.Remove("4")
+	removed := trieNode.Subtract(other)
+	if removed != 2 {
+		t.Fatalf("\nExpected: %v\nGot: %v", 2, removed)
+	}
+	expected := indexTrie()
+	expected.Remove("2")
+	expected.Remove("5")
+	diff := cmp.Diff(nodePrefixes(expected), nodePrefixes(trieNode))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	for method, search := range searcherCases {
+		diff := cmp.Diff(search(expected), search(trieNode))
+		if diff != "" {
+			t.Fatalf("%s: %s", method, diff)
+		}
+	}
+}
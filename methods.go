@@ -1,23 +1,86 @@
 package trie
 
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
 // NodeInterface is the interface satisfied by the Trie
 type NodeInterface interface {
 	// Add a new object to the Trie
 	// the remove string list parameter will remove the patterns and transform them in spaces
 	// so if the pattern is found in the middle of a word, then it will became two words with the pattern removed
+	// an ID that is added again keeps the name of the first time it was added
 	Add(id, name string, remove ...string)
+	// Add every document in the same way as Add, the names are cleaned and the words are inserted in parallel
+	AddBatch(docs []Document, remove ...string)
+	// Remove the object of the ID from the Trie, the words removed are the ones inserted when it was added
+	// it returns false if the ID is not in the Trie
+	Remove(id string) bool
+	SearcherInterface
+}
+
+// SearcherInterface has the methods that only read the Trie, they are also implemented by the read only Index
+type SearcherInterface interface {
 	// Checks if the Trie has at least one object
 	IsFilled() bool
 	// Checks if word is in the Trie
 	HasWord(word string) bool
 	// Based on a word, get the possible words following from that
 	GetPossibleWords(word string) []string
+	// Based on a prefix, get the k words most inserted that start with it, without ordering every possible word
+	Complete(prefix string, k int) []string
+	// Based on a phrase being typed, get the k best documents and phrases using all the words but the last as complete words
+	// and the last one as a prefix
+	AutocompletePhrase(input string, k int) PhraseCompletion
 	// Based on a word, get the correct matching words that had been inserted
 	// if the word is not found than the possible words are appended
 	SearchByRelevance(phrase string) []SearchData
+	// It is the same as SearchByRelevance, but the search stops with ErrCanceled when the context is done
+	// and with ErrTooManyCandidates, before the documents of the words are intersected, when every word has more documents than the maximum of the options
+	SearchContext(ctx context.Context, phrase string, opts SearchOptions) (SearchResult, error)
+	// Based on a phrase and the ID of a document, get the nodes of the terms and the keys used to rank the document
+	Explain(phrase, id string) Explanation
+	// It is the same as SearchByRelevance, but the results are returned by an iterator and only ordered while they are read
+	IterSearch(phrase string) Iterator
+	// Based on a prefix, visit every word that starts with it in increasing order with the IDs of its documents
+	// the walk stops when the visit returns false
+	WalkPrefix(prefix string, visit func(word string, ids []string) bool)
 	// It is the same as Search by name, but is paginated the final slice is paginated and ordered by its name
 	// The pagination returned has the total data and the number of page items
 	SearchByRelevancePaginated(phrase string, pagination Pagination) ([]SearchData, Pagination)
+	// It is the same as SearchByRelevance, but every result has the offsets of the matched words in its name
+	// the remove list must be the same used when adding the data
+	SearchByRelevanceHighlighted(phrase string, remove ...string) []HighlightedData
+	// Search the fragments of the phrase anywhere inside the words, not only as a prefix
+	// it is ordered in the same way as SearchByRelevance
+	SearchInfix(phrase string) []SearchData
+	// Search the words matching the wildcard patterns of the phrase, '*' matches any sequence of runes and '?' a single rune
+	SearchPattern(phrase string) []SearchData
+	// Search the words matching the regexp, the branches that cannot match an anchored prefix are not visited
+	SearchRegexp(re *regexp.Regexp) []SearchData
+}
+
+// Iterator returns the results of a search one by one
+// Next must be called before every Value, and Err returns the error that stopped the iteration, if any
+type Iterator interface {
+	Next() bool
+	Value() SearchData
+	Err() error
+}
+
+// Observer receives the changes and searches of a trie with their duration, it is set with SetObserver
+// it is called while the operation holds the trie, so it must be fast, and safe for concurrent use if the trie is
+// used by many goroutines. documents is the number of documents in the trie after the operation
+type Observer interface {
+	// Called after a document is added with the number of its words that were inserted
+	ObserveAdd(words, documents int, took time.Duration)
+	// Called after a document is removed, removed is false if the ID was not in the trie
+	ObserveRemove(removed bool, documents int, took time.Duration)
+	// Called after SearchContext, SearchByRelevance and SearchByRelevancePaginated with the number of documents
+	// that matched the phrase and the number of hits returned, the candidates of a search with too many are the ones of its smallest term
+	ObserveSearch(candidates, results, documents int, took time.Duration, err error)
 }
 
 // NodeHelperInterface is an extra interface that the trie implements
@@ -33,14 +96,19 @@ type NodeHelperInterface interface {
 	GetMaximumSizeOfPossibleIds() int
 	// Get the maximum node size of correct IDs
 	GetMaximumSizeOfCorrectIds() int
-	// Based on a word, print data from the root of the trie until it reaches the final rune
-	PrintPathToWord(word string)
-	// It is the same as Search by name, but is paginated the final slice is paginated and ordered by its name
-	// The pagination returned has the total data and the number of page items
-	PrintWordData(word string)
+	// Get the number of nodes, words, documents and postings, the largest postings and an estimate of the memory used
+	Stats() Stats
+	// Based on a word, get the data of its node, it returns false if no word passes by it
+	NodeInfo(word string) (NodeInfo, bool)
+	// Based on a word, get the data of the nodes of every prefix from its first rune until the word
+	// it stops at the first prefix that is not in the trie
+	PathTo(word string) []NodeInfo
 }
 
 // NewNode returns a Trie ready to be used
 func NewNode() *Node {
-	return &Node{children: make(map[rune]*Node)}
+	return &Node{
+		children:  make(map[rune]*Node),
+		documents: &documentTable{ordinals: make(map[string]uint32)},
+	}
 }
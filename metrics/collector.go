@@ -0,0 +1,170 @@
+// Package metrics implements a trie.Observer that counts the operations of a trie and serves them in the Prometheus text format
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Upper bounds of the buckets of the durations in seconds and of the number of documents of the searches
+var (
+	durationBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+	countBuckets    = []float64{0, 1, 10, 100, 1000, 10000, 100000}
+)
+
+// Collector counts the changes and searches of a trie, it is set as the observer of the trie
+// and serves the counters, the histograms and the number of documents in the Prometheus text format
+// It is safe for concurrent use, but the number of documents is the one reported by the last operation, so every trie needs its own collector
+type Collector struct {
+	mu               sync.Mutex
+	documents        int
+	adds             int
+	wordsAdded       int
+	removes          int
+	removesMissing   int
+	searches         int
+	searchErrors     int
+	addDuration      histogram
+	removeDuration   histogram
+	searchDuration   histogram
+	searchCandidates histogram
+	searchResults    histogram
+}
+
+// histogram counts the observations of every bucket, counts[i] has the observations not bigger than bounds[i]
+type histogram struct {
+	bounds []float64
+	counts []int
+	count  int
+	sum    float64
+}
+
+// NewCollector returns a collector without observations
+func NewCollector() *Collector {
+	return &Collector{
+		addDuration:      newHistogram(durationBuckets),
+		removeDuration:   newHistogram(durationBuckets),
+		searchDuration:   newHistogram(durationBuckets),
+		searchCandidates: newHistogram(countBuckets),
+		searchResults:    newHistogram(countBuckets),
+	}
+}
+
+func newHistogram(bounds []float64) histogram {
+	return histogram{bounds: bounds, counts: make([]int, len(bounds))}
+}
+
+// SetDocuments sets the number of documents of a trie that already had documents when the collector was set
+func (c *Collector) SetDocuments(documents int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.documents = documents
+}
+
+// ObserveAdd counts the document and the words added
+func (c *Collector) ObserveAdd(words, documents int, took time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.adds++
+	c.wordsAdded += words
+	c.documents = documents
+	c.addDuration.observe(took.Seconds())
+}
+
+// ObserveRemove counts the removal, the IDs that were not in the trie are also counted as missing
+func (c *Collector) ObserveRemove(removed bool, documents int, took time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removes++
+	if !removed {
+		c.removesMissing++
+	}
+	c.documents = documents
+	c.removeDuration.observe(took.Seconds())
+}
+
+// ObserveSearch counts the search and its documents, the searches that failed are also counted as errors
+func (c *Collector) ObserveSearch(candidates, results, documents int, took time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.searches++
+	if err != nil {
+		c.searchErrors++
+	}
+	c.documents = documents
+	c.searchDuration.observe(took.Seconds())
+	c.searchCandidates.observe(float64(candidates))
+	c.searchResults.observe(float64(results))
+}
+
+func (h *histogram) observe(value float64) {
+	for i, bound := range h.bounds {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := &metricWriter{w: w}
+	m.metric("trie_documents", "gauge", "Number of documents in the trie after the last operation.", float64(c.documents))
+	m.metric("trie_adds_total", "counter", "Number of documents added.", float64(c.adds))
+	m.metric("trie_words_added_total", "counter", "Number of words of the documents added.", float64(c.wordsAdded))
+	m.metric("trie_removes_total", "counter", "Number of documents removed, including the IDs that were not in the trie.", float64(c.removes))
+	m.metric("trie_removes_missing_total", "counter", "Number of removed IDs that were not in the trie.", float64(c.removesMissing))
+	m.metric("trie_searches_total", "counter", "Number of searches.", float64(c.searches))
+	m.metric("trie_search_errors_total", "counter", "Number of searches that were canceled or had too many candidates.", float64(c.searchErrors))
+	m.histogram("trie_add_duration_seconds", "Duration of the additions of documents.", &c.addDuration)
+	m.histogram("trie_remove_duration_seconds", "Duration of the removals of documents.", &c.removeDuration)
+	m.histogram("trie_search_duration_seconds", "Duration of the searches.", &c.searchDuration)
+	m.histogram("trie_search_candidates", "Number of documents that matched the phrase of the searches.", &c.searchCandidates)
+	m.histogram("trie_search_results", "Number of hits returned by the searches.", &c.searchResults)
+	return m.n, m.err
+}
+
+// metricWriter writes the lines of the metrics and keeps the first error, the lines after an error are not written
+type metricWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (m *metricWriter) printf(format string, args ...interface{}) {
+	if m.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(m.w, format, args...)
+	m.n += int64(n)
+	m.err = err
+}
+
+func (m *metricWriter) metric(name, kind, help string, value float64) {
+	m.printf("# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, formatFloat(value))
+}
+
+func (m *metricWriter) histogram(name, help string, h *histogram) {
+	m.printf("# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for i, bound := range h.bounds {
+		m.printf("%s_bucket{le=\"%s\"} %d\n", name, formatFloat(bound), h.counts[i])
+	}
+	m.printf("%s_bucket{le=\"+Inf\"} %d\n%s_sum %s\n%s_count %d\n", name, h.count, name, formatFloat(h.sum), name, h.count)
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
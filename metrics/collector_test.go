@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"trie-go/trie"
+)
+
+func Test_Collector(t *testing.T) {
+	collector := NewCollector()
+	trieNode := trie.NewNode()
+	trieNode.SetObserver(collector)
+	trieNode.Add("1", "Direito Penal")
+	trieNode.Add("2", "Direito Civil")
+	trieNode.SearchByRelevance("direito")
+	trieNode.SearchContext(context.Background(), "direito", trie.SearchOptions{MaxCandidates: 1})
+	trieNode.Remove("2")
+	trieNode.Remove("3")
+
+	recorder := httptest.NewRecorder()
+	collector.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Fatalf("\nExpected: %v\nGot: %v", "text/plain; version=0.0.4", contentType)
+	}
+	body := recorder.Body.String()
+
+	cases := map[string]struct {
+		line string
+	}{
+		"Documents":              {"trie_documents 1\n"},
+		"Documents type":         {"# TYPE trie_documents gauge\n"},
+		"Adds":                   {"trie_adds_total 2\n"},
+		"Adds type":              {"# TYPE trie_adds_total counter\n"},
+		"Words added":            {"trie_words_added_total 4\n"},
+		"Removes":                {"trie_removes_total 2\n"},
+		"Removes missing":        {"trie_removes_missing_total 1\n"},
+		"Searches":               {"trie_searches_total 2\n"},
+		"Search errors":          {"trie_search_errors_total 1\n"},
+		"Search duration type":   {"# TYPE trie_search_duration_seconds histogram\n"},
+		"Search duration count":  {"trie_search_duration_seconds_count 2\n"},
+		"Search duration bucket": {"trie_search_duration_seconds_bucket{le=\"+Inf\"} 2\n"},
+		"Candidates bucket":      {"trie_search_candidates_bucket{le=\"1\"} 0\ntrie_search_candidates_bucket{le=\"10\"} 2\n"},
+		"Candidates sum":         {"trie_search_candidates_sum 4\n"},
+		"Results zero bucket":    {"trie_search_results_bucket{le=\"0\"} 1\n"},
+		"Results sum":            {"trie_search_results_sum 2\n"},
+		"Add duration count":     {"trie_add_duration_seconds_count 2\n"},
+		"Remove duration count":  {"trie_remove_duration_seconds_count 2\n"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if !strings.Contains(body, tc.line) {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.line, body)
+			}
+		})
+	}
+}
+
+func Test_Histogram(t *testing.T) {
+	h := newHistogram([]float64{0.001, 0.01, 0.1})
+	for _, took := range []time.Duration{500 * time.Microsecond, time.Millisecond, 50 * time.Millisecond, time.Second} {
+		h.observe(took.Seconds())
+	}
+	expected := []int{2, 2, 3}
+	for i, count := range expected {
+		if h.counts[i] != count {
+			t.Fatalf("\nExpected: %v\nGot: %v", expected, h.counts)
+		}
+	}
+	if h.count != 4 {
+		t.Fatalf("\nExpected: %v\nGot: %v", 4, h.count)
+	}
+}
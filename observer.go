@@ -0,0 +1,29 @@
+package trie
+
+import (
+	"errors"
+	"time"
+)
+
+// SetObserver sets the observer called after every change and search of the trie, a nil observer removes it
+func (t *Node) SetObserver(observer Observer) {
+	t.observer = observer
+}
+
+// SetObserver sets the observer called after every search of the index, a nil observer removes it
+// the documents of the index include the ones that were removed before it was written
+func (idx *Index) SetObserver(observer Observer) {
+	idx.observer = observer
+}
+
+func observeSearch(observer Observer, start time.Time, p *postings, result SearchResult, documents int, err error) {
+	candidates := 0
+	var tooMany candidatesError
+	switch {
+	case p != nil:
+		candidates = p.len()
+	case errors.As(err, &tooMany):
+		candidates = tooMany.candidates
+	}
+	observer.ObserveSearch(candidates, len(result.Hits), documents, time.Since(start), err)
+}
@@ -0,0 +1,81 @@
+package trie
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// recorder keeps the calls of the observer without their durations
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) ObserveAdd(words, documents int, took time.Duration) {
+	r.calls = append(r.calls, fmt.Sprintf("add %d words, %d documents", words, documents))
+}
+
+func (r *recorder) ObserveRemove(removed bool, documents int, took time.Duration) {
+	r.calls = append(r.calls, fmt.Sprintf("remove %t, %d documents", removed, documents))
+}
+
+func (r *recorder) ObserveSearch(candidates, results, documents int, took time.Duration, err error) {
+	r.calls = append(r.calls, fmt.Sprintf("search %d candidates, %d results, %d documents, %v", candidates, results, documents, err))
+}
+
+func Test_Observer(t *testing.T) {
+	trieNode := NewNode()
+	observer := &recorder{}
+	trieNode.SetObserver(observer)
+	trieNode.Add("1", "Direito Penal")
+	trieNode.Add("2", "Direito de Família")
+	trieNode.Add("3", "de")
+	trieNode.SearchByRelevance("direito")
+	trieNode.SearchContext(context.Background(), "direito", SearchOptions{Limit: 1})
+	trieNode.SearchContext(context.Background(), "direito", SearchOptions{MaxCandidates: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	trieNode.SearchContext(ctx, "direito", SearchOptions{})
+	trieNode.SearchByRelevancePaginated("xyz", Pagination{PerPage: 10, Page: 1})
+	trieNode.Remove("1")
+	trieNode.Remove("1")
+	trieNode.SetObserver(nil)
+	trieNode.Add("4", "Direito Civil")
+
+	expected := []string{
+		"add 2 words, 1 documents",
+		"add 2 words, 2 documents",
+		"add 0 words, 2 documents",
+		"search 2 candidates, 2 results, 2 documents, <nil>",
+		"search 2 candidates, 1 results, 2 documents, <nil>",
+		"search 2 candidates, 0 results, 2 documents, trie: too many candidates",
+		"search 0 candidates, 0 results, 2 documents, trie: search canceled: context canceled",
+		"search 0 candidates, 0 results, 2 documents, <nil>",
+		"remove true, 1 documents",
+		"remove false, 1 documents",
+	}
+	diff := cmp.Diff(expected, observer.calls)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+func Test_IndexObserver(t *testing.T) {
+	index := loadedIndex(t)
+	observer := &recorder{}
+	index.SetObserver(observer)
+	index.SearchByRelevance("direito penal")
+	index.SearchContext(context.Background(), "dire", SearchOptions{Limit: 2})
+
+	expected := []string{
+		"search 2 candidates, 2 results, 7 documents, <nil>",
+		"search 5 candidates, 2 results, 7 documents, <nil>",
+	}
+	diff := cmp.Diff(expected, observer.calls)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
@@ -0,0 +1,147 @@
+package trie
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// Wildcards accepted by SearchPattern
+const (
+	anyRunes = '*'
+	anyRune  = '?'
+)
+
+// SearchPattern return the matching IDs for a phrase of wildcard patterns
+// '*' matches any sequence of runes and '?' matches exactly one rune, every pattern must match a complete word
+// The result is ordered in the same way as SearchByRelevance
+func (t *Node) SearchPattern(phrase string) []SearchData {
+	return orderByRelevance(t.documents, t.searchPatternPostings(phrase))
+}
+
+// searchPatternPostings returns the documents that have a word matching every pattern of the phrase
+func (t *Node) searchPatternPostings(phrase string) *postings {
+	var postingList []*postings
+	for _, word := range strings.Fields(phrase) {
+		pattern := cleanPattern(word)
+		if len(pattern) == 0 {
+			continue
+		}
+		var found []*postings
+		t.walkPattern(pattern, stepPattern(pattern, nil, 0), &found)
+		postingList = append(postingList, unionPostingList(found))
+	}
+	return intersectPostingList(postingList)
+}
+
+// SearchRegexp return the IDs that have at least one word matched by the regexp
+// The regexp is matched against the cleaned words, in lower case and without accents
+// If the regexp is anchored in the beginning with a literal prefix, only the branches with that prefix are visited
+func (t *Node) SearchRegexp(re *regexp.Regexp) []SearchData {
+	return orderByRelevance(t.documents, t.searchRegexpPostings(re))
+}
+
+// searchRegexpPostings returns the documents that have a word matched by the regexp
+func (t *Node) searchRegexpPostings(re *regexp.Regexp) *postings {
+	var found []*postings
+	t.walkRegexp(re, regexpPrefix(re), &found)
+	return unionPostingList(found)
+}
+
+// cleanPattern cleans the literal parts of the pattern and keeps the wildcards
+func cleanPattern(word string) []rune {
+	var pattern []rune
+	literal := ""
+	for _, runeValue := range word {
+		if runeValue != anyRunes && runeValue != anyRune {
+			literal += string(runeValue)
+			continue
+		}
+		pattern = append(pattern, []rune(cleanString(literal))...)
+		pattern = append(pattern, runeValue)
+		literal = ""
+	}
+	return append(pattern, []rune(cleanString(literal))...)
+}
+
+// stepPattern returns the states of the pattern reached after reading the rune
+// a state is the index of the pattern that is still to be matched, when states is nil the initial state is returned
+// it returns nil when no state is reached, so no word under the current node can match the pattern
+func stepPattern(pattern []rune, states []bool, runeValue rune) []bool {
+	next := make([]bool, len(pattern)+1)
+	if states == nil {
+		next[0] = true
+	} else {
+		reached := false
+		for i, ok := range states {
+			if !ok || i == len(pattern) {
+				continue
+			}
+			switch pattern[i] {
+			case anyRunes:
+				next[i] = true
+				reached = true
+			case anyRune, runeValue:
+				next[i+1] = true
+				reached = true
+			}
+		}
+		if !reached {
+			return nil
+		}
+	}
+	for i := range pattern {
+		if next[i] && pattern[i] == anyRunes {
+			next[i+1] = true
+		}
+	}
+	return next
+}
+
+func (t *Node) walkPattern(pattern []rune, states []bool, found *[]*postings) {
+	for _, child := range t.children {
+		next := states
+		for _, runeValue := range child.label(t) {
+			if next = stepPattern(pattern, next, runeValue); next == nil {
+				break
+			}
+		}
+		if next == nil {
+			continue
+		}
+		if child.isWord && next[len(pattern)] {
+			*found = append(*found, &child.correctData)
+		}
+		child.walkPattern(pattern, next, found)
+	}
+}
+
+func (t *Node) walkRegexp(re *regexp.Regexp, prefix string, found *[]*postings) {
+	for _, child := range t.children {
+		if !strings.HasPrefix(child.currentWord, prefix) && !strings.HasPrefix(prefix, child.currentWord) {
+			continue
+		}
+		if child.isWord && re.MatchString(child.currentWord) {
+			*found = append(*found, &child.correctData)
+		}
+		child.walkRegexp(re, prefix, found)
+	}
+}
+
+// regexpPrefix returns the literal that every match of the regexp must start with
+// it is empty if the regexp is not anchored in the beginning of the text
+func regexpPrefix(re *regexp.Regexp) string {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	parsed = parsed.Simplify()
+	if parsed.Op != syntax.OpConcat || len(parsed.Sub) < 2 || parsed.Sub[0].Op != syntax.OpBeginText {
+		return ""
+	}
+	literal := parsed.Sub[1]
+	if literal.Op != syntax.OpLiteral || literal.Flags&syntax.FoldCase != 0 {
+		return ""
+	}
+	return string(literal.Rune)
+}
@@ -0,0 +1,87 @@
+package trie
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_SearchPattern(t *testing.T) {
+	trieNode := NewNode()
+	trieNode.Add("1", "Direito Penal")
+	trieNode.Add("2", "Direto ao Ponto")
+	trieNode.Add("3", "Direito Penal Militar")
+	trieNode.Add("4", "Administração")
+
+	cases := map[string]struct {
+		pattern  string
+		expected []SearchData
+	}{
+		"Any sequence of runes":       {"dir*to", []SearchData{{"1", "Direito Penal"}, {"2", "Direto ao Ponto"}, {"3", "Direito Penal Militar"}}},
+		"Exactly one rune":            {"pen?l", []SearchData{{"1", "Direito Penal"}, {"3", "Direito Penal Militar"}}},
+		"Multiple patterns":           {"dir*to pen?l", []SearchData{{"1", "Direito Penal"}, {"3", "Direito Penal Militar"}}},
+		"Pattern with accents":        {"*ção", []SearchData{{"4", "Administração"}}},
+		"Pattern must match the word": {"dire", nil},
+		"Only wildcards":              {"???????", []SearchData{{"1", "Direito Penal"}, {"3", "Direito Penal Militar"}}},
+		"Pattern not found":           {"x*z", nil},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expected, trieNode.SearchPattern(tc.pattern))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_SearchRegexp(t *testing.T) {
+	trieNode := NewNode()
+	trieNode.Add("1", "Direito Penal")
+	trieNode.Add("2", "Direto ao Ponto")
+	trieNode.Add("3", "Direito Penal Militar")
+	trieNode.Add("4", "Administração")
+
+	cases := map[string]struct {
+		re       *regexp.Regexp
+		expected []SearchData
+	}{
+		"Anchored with a prefix":  {regexp.MustCompile(`^dire(i)?to$`), []SearchData{{"1", "Direito Penal"}, {"2", "Direto ao Ponto"}, {"3", "Direito Penal Militar"}}},
+		"Not anchored":            {regexp.MustCompile(`cao`), []SearchData{{"4", "Administração"}}},
+		"Alternation":             {regexp.MustCompile(`^(ponto|militar)$`), []SearchData{{"2", "Direto ao Ponto"}, {"3", "Direito Penal Militar"}}},
+		"Case insensitive prefix": {regexp.MustCompile(`(?i)^PEN`), []SearchData{{"1", "Direito Penal"}, {"3", "Direito Penal Militar"}}},
+		"Regexp not found":        {regexp.MustCompile(`^x`), nil},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expected, trieNode.SearchRegexp(tc.re))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_RegexpPrefix(t *testing.T) {
+	cases := map[string]struct {
+		re       string
+		expected string
+	}{
+		"Anchored literal":   {`^dir.*to$`, "dir"},
+		"Not anchored":       {`dir.*to$`, ""},
+		"Case insensitive":   {`(?i)^dir`, ""},
+		"Anchored no prefix": {`^(dir|pen)`, ""},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			prefix := regexpPrefix(regexp.MustCompile(tc.re))
+			if prefix != tc.expected {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.expected, prefix)
+			}
+		})
+	}
+}
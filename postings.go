@@ -0,0 +1,201 @@
+package trie
+
+import (
+	"context"
+	"sort"
+)
+
+// ordinal returns the ordinal of the document, a new ordinal is created for an ID that was never added
+// the name of the document is the one of the first time it was added
+func (d *documentTable) ordinal(id, name string) uint32 {
+	if doc, ok := d.ordinals[id]; ok {
+		return doc
+	}
+	doc := uint32(len(d.ids))
+	d.ordinals[id] = doc
+	d.ids = append(d.ids, id)
+	d.names = append(d.names, name)
+	d.words = append(d.words, "")
+	return doc
+}
+
+// addWords appends the original words inserted for the document to the ones of the previous times it was added
+// the words never have spaces, as they are the fields of the name
+func (d *documentTable) addWords(doc uint32, words string) {
+	if d.words[doc] == "" {
+		d.words[doc] = words
+		return
+	}
+	d.words[doc] += " " + words
+}
+
+// len returns the number of documents in the postings
+func (p *postings) len() int {
+	return p.docs.cardinality()
+}
+
+// add inserts the position of the document in the postings
+// the positions are kept in the same order of the documents in the bitmap, and the ones of a document are sorted without repeated values
+func (p *postings) add(doc uint32, position int) {
+	if last, ok := p.docs.last(); !ok || doc > last {
+		p.docs.add(doc)
+		p.positions = append(p.positions, []int{position})
+		return
+	}
+	if !p.docs.add(doc) {
+		i := p.docs.rank(doc) - 1
+		p.positions[i] = insertPosition(p.positions[i], position)
+		return
+	}
+	i := p.docs.rank(doc) - 1
+	p.positions = append(p.positions, nil)
+	copy(p.positions[i+1:], p.positions[i:])
+	p.positions[i] = []int{position}
+}
+
+// remove deletes the document and its positions from the postings
+func (p *postings) remove(doc uint32) {
+	if !p.docs.contains(doc) {
+		return
+	}
+	i := p.docs.rank(doc) - 1
+	p.docs.remove(doc)
+	p.positions = append(p.positions[:i], p.positions[i+1:]...)
+}
+
+// unionPostings returns the documents that are in any of the postings, the positions of repeated documents are merged
+func unionPostings(i, j *postings) *postings {
+	return unionPostingList([]*postings{i, j})
+}
+
+// unionPostingList returns the documents that are in any of the postings, the positions of repeated documents are merged
+// the documents of all postings are sorted together, so the bitmap of the union is built in increasing order
+func unionPostingList(postingList []*postings) *postings {
+	type entry struct {
+		doc      uint32
+		position []int
+	}
+	var all []entry
+	for _, p := range postingList {
+		k := 0
+		p.docs.each(func(doc uint32) bool {
+			all = append(all, entry{doc: doc, position: p.positions[k]})
+			k++
+			return true
+		})
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].doc < all[j].doc
+	})
+	union := &postings{}
+	for i, e := range all {
+		if i > 0 && all[i-1].doc == e.doc {
+			last := len(union.positions) - 1
+			union.positions[last] = mergePositions(union.positions[last], e.position)
+			continue
+		}
+		union.docs.add(e.doc)
+		union.positions = append(union.positions, mergePositions(nil, e.position))
+	}
+	return union
+}
+
+// intersectPostings returns the documents that are in both postings with the positions of both, a nil postings has no documents
+// the merged positions are new slices, so the postings are never changed by a search
+func intersectPostings(i, j *postings) *postings {
+	if i == nil || j == nil {
+		return &postings{}
+	}
+	intersection := &postings{docs: *and(&i.docs, &j.docs)}
+	// the indexes of every document in both postings are found first, so the merged positions are allocated together
+	indexes := make([][2]int, 0, intersection.len())
+	size := 0
+	cursorI, cursorJ := i.docs.cursor(), j.docs.cursor()
+	intersection.docs.each(func(doc uint32) bool {
+		k, l := cursorI.index(doc), cursorJ.index(doc)
+		indexes = append(indexes, [2]int{k, l})
+		size += len(i.positions[k]) + len(j.positions[l])
+		return true
+	})
+	all := make([]int, 0, size)
+	intersection.positions = make([][]int, len(indexes))
+	for n, index := range indexes {
+		start := len(all)
+		all = append(append(all, i.positions[index[0]]...), j.positions[index[1]]...)
+		position := all[start:len(all):len(all)]
+		sort.Ints(position)
+		intersection.positions[n] = position
+	}
+	return intersection
+}
+
+// intersectPostingList intersects all the postings, a nil postings in the beginning of the list is ignored
+func intersectPostingList(postingList []*postings) *postings {
+	final, _ := intersectListContext(context.Background(), postingList)
+	return final
+}
+
+// intersectListContext intersects the postings and stops with ErrCanceled if the context is done before an intersection
+func intersectListContext(ctx context.Context, postingList []*postings) (*postings, error) {
+	var final *postings
+	for _, p := range postingList {
+		if err := checkContext(ctx); err != nil {
+			return nil, err
+		}
+		if final == nil {
+			final = p
+			continue
+		}
+		final = intersectPostings(final, p)
+	}
+	return final, nil
+}
+
+// document returns the ID and the name of the document ordinal
+func (d *documentTable) document(doc uint32) (string, string) {
+	return d.ids[doc], d.names[doc]
+}
+
+// idList returns the external IDs of the postings
+func idList(source documentSource, p *postings) []string {
+	ids := make([]string, 0, p.len())
+	p.docs.each(func(doc uint32) bool {
+		id, _ := source.document(doc)
+		ids = append(ids, id)
+		return true
+	})
+	return ids
+}
+
+// orderByRelevance returns the documents of the postings ordered by the position of the words and the name
+func orderByRelevance(source documentSource, p *postings) []SearchData {
+	values := relevanceValues(source, p)
+	sort.Sort(values)
+	return values.searchData()
+}
+
+// searchData returns the ID and name of the values
+func (n byRelevance) searchData() []SearchData {
+	var orderedSearchData []SearchData
+	for _, value := range n {
+		orderedSearchData = append(orderedSearchData, SearchData{ID: value.id, Name: value.name})
+	}
+	return orderedSearchData
+}
+
+// relevanceValues returns the data used to order the documents of the postings
+func relevanceValues(source documentSource, p *postings) byRelevance {
+	if p == nil {
+		return nil
+	}
+	data := make([]internalOrderData, p.len())
+	values := make(byRelevance, 0, p.len())
+	p.docs.each(func(doc uint32) bool {
+		value := &data[len(values)]
+		value.id, value.name = source.document(doc)
+		value.position = p.positions[len(values)]
+		values = append(values, value)
+		return true
+	})
+	return values
+}
@@ -0,0 +1,179 @@
+package trie
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// entry is a document with its positions, used to build and compare postings in the tests
+type entry struct {
+	Doc      uint32
+	Position []int
+}
+
+func newPostings(entries ...entry) *postings {
+	p := &postings{}
+	for _, e := range entries {
+		for _, position := range e.Position {
+			p.add(e.Doc, position)
+		}
+	}
+	return p
+}
+
+func postingEntries(p *postings) []entry {
+	var entries []entry
+	p.docs.each(func(doc uint32) bool {
+		entries = append(entries, entry{doc, p.positions[len(entries)]})
+		return true
+	})
+	return entries
+}
+
+func Test_AddPosting(t *testing.T) {
+	cases := map[string]struct {
+		entries  []entry
+		doc      uint32
+		position int
+		expected []entry
+	}{
+		"Empty postings":      {nil, 1, 0, []entry{{1, []int{0}}}},
+		"New last document":   {[]entry{{1, []int{0}}}, 2, 3, []entry{{1, []int{0}}, {2, []int{3}}}},
+		"New first document":  {[]entry{{2, []int{0}}}, 1, 3, []entry{{1, []int{3}}, {2, []int{0}}}},
+		"New middle document": {[]entry{{1, []int{0}}, {3, []int{0}}}, 2, 1, []entry{{1, []int{0}}, {2, []int{1}}, {3, []int{0}}}},
+		"Document already in": {[]entry{{1, []int{0}}, {3, []int{0}}}, 3, 4, []entry{{1, []int{0}}, {3, []int{0, 4}}}},
+		"Other container":     {[]entry{{1, []int{0}}, {1 << 20, []int{0}}}, 1 << 17, 2, []entry{{1, []int{0}}, {1 << 17, []int{2}}, {1 << 20, []int{0}}}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := newPostings(tc.entries...)
+			p.add(tc.doc, tc.position)
+			diff := cmp.Diff(tc.expected, postingEntries(p))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_UnionAndIntersectPostings(t *testing.T) {
+	cases := map[string]struct {
+		i, j                 []entry
+		expectedUnion        []entry
+		expectedIntersection []entry
+	}{
+		"Empty postings":      {nil, nil, nil, nil},
+		"One empty postings":  {[]entry{{1, []int{2}}}, nil, []entry{{1, []int{2}}}, nil},
+		"Different documents": {[]entry{{1, []int{0}}, {3, []int{1}}}, []entry{{2, []int{0}}}, []entry{{1, []int{0}}, {2, []int{0}}, {3, []int{1}}}, nil},
+		"Same documents":      {[]entry{{1, []int{0, 3}}, {2, []int{1}}}, []entry{{1, []int{3, 1}}, {3, []int{0}}}, []entry{{1, []int{0, 1, 3}}, {2, []int{1}}, {3, []int{0}}}, []entry{{1, []int{0, 1, 3, 3}}}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expectedUnion, postingEntries(unionPostings(newPostings(tc.i...), newPostings(tc.j...))))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			diff = cmp.Diff(tc.expectedIntersection, postingEntries(intersectPostings(newPostings(tc.i...), newPostings(tc.j...))))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_IntersectPostingList(t *testing.T) {
+	first := newPostings(entry{1, []int{0}}, entry{2, []int{1}})
+	cases := map[string]struct {
+		postingList []*postings
+		expected    []entry
+	}{
+		"Nil in the beginning": {[]*postings{nil, first}, []entry{{1, []int{0}}, {2, []int{1}}}},
+		"Nil in the middle":    {[]*postings{first, nil, first}, nil},
+		"Same postings":        {[]*postings{first, first}, []entry{{1, []int{0, 0}}, {2, []int{1, 1}}}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(tc.expected, postingEntries(intersectPostingList(tc.postingList)))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_DocumentTable(t *testing.T) {
+	trieNode := NewNode()
+	trieNode.Add("a", "Direito Penal")
+	trieNode.Add("b", "Direito Civil")
+	trieNode.Add("a", "Direito Penal Militar")
+
+	diff := cmp.Diff([]string{"a", "b"}, trieNode.documents.ids)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	diff = cmp.Diff([]string{"Direito Penal", "Direito Civil"}, trieNode.documents.names)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	diff = cmp.Diff([]string{"a", "b"}, trieNode.GetCorrectIDs("direito"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+// corpusTries keeps the corpora already built, so every benchmark of the same size uses the same trie
+var corpusTries = make(map[int]*Node)
+
+// corpusTrie returns a trie with the number of documents, every document has three words of a small vocabulary
+// so the nodes near the root have postings with a big part of the documents
+func corpusTrie(documents int) *Node {
+	if trieNode, ok := corpusTries[documents]; ok {
+		return trieNode
+	}
+	rng := rand.New(rand.NewSource(int64(documents)))
+	zipf := rand.NewZipf(rng, 1.1, 1, 199)
+	vocabulary := make([]string, 200)
+	for i := range vocabulary {
+		vocabulary[i] = syllables[i%len(syllables)] + syllables[(i/len(syllables))%len(syllables)] + syllables[(i*7)%len(syllables)]
+	}
+	trieNode := NewNode()
+	for i := 0; i < documents; i++ {
+		name := vocabulary[zipf.Uint64()] + " " + vocabulary[zipf.Uint64()] + " " + vocabulary[rng.Intn(len(vocabulary))]
+		trieNode.Add(strconv.Itoa(i), name)
+	}
+	corpusTries[documents] = trieNode
+	return trieNode
+}
+
+func Benchmark_IntersectPrefixes(b *testing.B) {
+	for _, documents := range []int{100000, 1000000} {
+		b.Run(fmt.Sprintf("documents=%d", documents), func(b *testing.B) {
+			trieNode := corpusTrie(documents)
+			first, second := trieNode.getNode("ba"), trieNode.getNode("be")
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				intersectPostingList([]*postings{&first.possibleData, &second.possibleData})
+			}
+		})
+	}
+}
+
+func Benchmark_CountIntersection(b *testing.B) {
+	for _, documents := range []int{100000, 1000000} {
+		b.Run(fmt.Sprintf("documents=%d", documents), func(b *testing.B) {
+			trieNode := corpusTrie(documents)
+			first, second := trieNode.getNode("ba"), trieNode.getNode("be")
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_ = and(&first.possibleData.docs, &second.possibleData.docs).cardinality()
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package trie
+
+import "unicode/utf8"
+
+// label returns the runes in the edge from the parent to the node
+func (t *Node) label(parent *Node) string {
+	return t.currentWord[len(parent.currentWord):]
+}
+
+// seek follows the cleaned word from the node while it matches the edges of the trie
+// it returns the last node completely matched, the child where the walk stopped inside its edge, if any,
+// and the size of the cleaned word that was matched
+func (t *Node) seek(cleanedString string) (*Node, *Node, int) {
+	node := t
+	for len(node.currentWord) < len(cleanedString) {
+		rest := cleanedString[len(node.currentWord):]
+		runeValue, _ := utf8.DecodeRuneInString(rest)
+		child, ok := node.children[runeValue]
+		if !ok {
+			return node, nil, len(node.currentWord)
+		}
+		size := commonPrefixLength(child.label(node), rest)
+		if size < len(child.label(node)) {
+			return node, child, len(node.currentWord) + size
+		}
+		node = child
+	}
+	return node, nil, len(node.currentWord)
+}
+
+// getNode returns the node of the cleaned word or nil if it is not in the trie
+// when the word ends inside an edge, a temporary node with the same data an uncompressed trie would have is returned
+func (t *Node) getNode(cleanedString string) *Node {
+	node, next, size := t.seek(cleanedString)
+	if size < len(cleanedString) {
+		return nil
+	}
+	if next != nil {
+		return virtualNode(cleanedString, next)
+	}
+	return node
+}
+
+// getDeepestNode returns the node of the longest prefix of the cleaned word that is in the trie
+func (t *Node) getDeepestNode(cleanedString string) *Node {
+	node, next, size := t.seek(cleanedString)
+	if next != nil && size > len(node.currentWord) {
+		return virtualNode(cleanedString[:size], next)
+	}
+	return node
+}
+
+// virtualNode returns a node for a prefix that ends inside the edge of the next node
+// every word that passes by the prefix also passes by or ends in the next node
+func virtualNode(prefix string, next *Node) *Node {
+	runeValue, _ := utf8.DecodeRuneInString(next.currentWord[len(prefix):])
+	node := &Node{
+		currentWord:   prefix,
+		children:      map[rune]*Node{runeValue: next},
+		possibleWords: make(map[string]int),
+		possibleData:  *unionPostings(&next.possibleData, &next.correctData),
+		topWords:      next.topWords,
+	}
+	for word, count := range next.possibleWords {
+		node.possibleWords[word] += count
+	}
+	for word, count := range next.correctWords {
+		node.possibleWords[word] += count
+	}
+	return node
+}
+
+// splitNode creates the node of the prefix in the middle of the edge of the child
+// the new node is the parent of the child and holds the words of the child as possible words
+func splitNode(child *Node, prefix string) *Node {
+	node := virtualNode(prefix, child)
+	node.topWords = append([]wordCount(nil), child.topWords...)
+	return node
+}
+
+// commonPrefixLength returns the size in bytes of the runes both strings start with
+func commonPrefixLength(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) {
+		runeA, size := utf8.DecodeRuneInString(a[i:])
+		runeB, _ := utf8.DecodeRuneInString(b[i:])
+		if runeA != runeB {
+			break
+		}
+		i += size
+	}
+	return i
+}
@@ -0,0 +1,108 @@
+package trie
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_CompressedNodes(t *testing.T) {
+	cases := map[string]struct {
+		names         []string
+		expectedNodes int
+	}{
+		"One word is one node":              {[]string{"direito"}, 2},
+		"Words split in a new node":         {[]string{"direito", "direcao"}, 4},
+		"Word ending inside an edge":        {[]string{"direito", "dire"}, 3},
+		"Word already compressed":           {[]string{"direito", "direito"}, 2},
+		"Words with accents split by runes": {[]string{"ação", "açúcar"}, 4},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			trieNode := &Node{children: make(map[rune]*Node)}
+			for i, name := range tc.names {
+				trieNode.insert(uint32(i), name, cleanString(name), 0)
+			}
+			if countNodes(trieNode) != tc.expectedNodes {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.expectedNodes, countNodes(trieNode))
+			}
+		})
+	}
+}
+
+func Test_SearchInsideEdges(t *testing.T) {
+	trieNode := NewNode()
+	trieNode.Add("1", "Direito")
+	trieNode.Add("2", "Direção")
+	trieNode.Add("3", "Dir")
+
+	cases := map[string]struct {
+		word                string
+		hasWord             bool
+		expectedPossibleIDs []string
+		expectedCorrectIDs  []string
+	}{
+		"Root of the split":     {"dir", true, []string{"1", "2"}, []string{"3"}},
+		"Inside the first edge": {"di", false, []string{"1", "2", "3"}, []string{}},
+		"Split node":            {"dire", false, []string{"1", "2"}, []string{}},
+		"Inside the last edge":  {"direit", false, []string{"1"}, []string{}},
+		"Complete word":         {"direito", true, []string{}, []string{"1"}},
+		"Past the word":         {"direitos", false, nil, nil},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if trieNode.HasWord(tc.word) != tc.hasWord {
+				t.Fatalf("\nExpected: %v\nGot: %v", tc.hasWord, trieNode.HasWord(tc.word))
+			}
+			possibleIDs := trieNode.GetPossibleIDs(tc.word)
+			sort.Strings(possibleIDs)
+			diff := cmp.Diff(tc.expectedPossibleIDs, possibleIDs)
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			diff = cmp.Diff(tc.expectedCorrectIDs, trieNode.GetCorrectIDs(tc.word))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+	if trieNode.GetMaximumSizeOfPossibleIds() != 3 {
+		t.Fatalf("\nExpected: %v\nGot: %v", 3, trieNode.GetMaximumSizeOfPossibleIds())
+	}
+}
+
+func Benchmark_AddMemory(b *testing.B) {
+	for _, words := range []int{10000, 100000} {
+		b.Run(fmt.Sprintf("words=%d", words), func(b *testing.B) {
+			var before, after runtime.MemStats
+			for i := 0; i < b.N; i++ {
+				delete(syntheticTries, words)
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				trieNode := syntheticTrie(words)
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				b.ReportMetric(float64(after.HeapAlloc-before.HeapAlloc), "heap-bytes")
+				b.ReportMetric(float64(countNodes(trieNode)), "nodes")
+			}
+			delete(syntheticTries, words)
+		})
+	}
+}
+
+// countNodes returns the number of nodes of the main trie and of the suffix trie
+func countNodes(t *Node) int {
+	count := 1
+	for _, child := range t.children {
+		count += countNodes(child)
+	}
+	if t.suffixes != nil {
+		count += countNodes(t.suffixes)
+	}
+	return count
+}
@@ -0,0 +1,123 @@
+package trie
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Remove takes the document of the ID out of the trie
+// the words inserted every time the document was added are removed from the nodes they passed by, with the remove list used then,
+// and the nodes left without words are deleted
+// It returns false if the ID is not in the trie
+func (t *Node) Remove(id string) bool {
+	if t.observer != nil {
+		start := time.Now()
+		removed := t.remove(id)
+		t.observer.ObserveRemove(removed, len(t.documents.ordinals), time.Since(start))
+		return removed
+	}
+	return t.remove(id)
+}
+
+func (t *Node) remove(id string) bool {
+	doc, ok := t.documents.ordinals[id]
+	if !ok {
+		return false
+	}
+	for _, word := range strings.Fields(t.documents.words[doc]) {
+		cleanedString := cleanString(word)
+		t.removeWord(doc, word, cleanedString)
+		t.removeSuffixes(doc, word, cleanedString)
+	}
+	t.documents.remove(doc)
+	return true
+}
+
+// removeSuffixes removes every suffix of the cleaned word from the suffix trie, as they were inserted by insertSuffixes
+func (t *Node) removeSuffixes(doc uint32, word, cleanedString string) {
+	if t.suffixes == nil {
+		return
+	}
+	eachSuffix(cleanedString, func(suffix string) {
+		t.suffixes.removeWord(doc, word, suffix)
+	})
+}
+
+// removeWord takes the document out of the nodes of the cleaned word and decreases the count of the word in every node
+// a node without documents is deleted from its parent and a node with a single child that is not a word is replaced by the child,
+// so the trie is compressed in the same way as if the word was never inserted
+func (t *Node) removeWord(doc uint32, word, cleanedString string) {
+	path := []*Node{t}
+	node := t
+	for len(node.currentWord) < len(cleanedString) {
+		rest := cleanedString[len(node.currentWord):]
+		runeValue, _ := utf8.DecodeRuneInString(rest)
+		child, ok := node.children[runeValue]
+		if !ok || !strings.HasPrefix(rest, child.label(node)) {
+			return
+		}
+		node = child
+		path = append(path, node)
+	}
+	if node == t || !node.isWord {
+		return
+	}
+	node.correctData.remove(doc)
+	decrementWord(node.correctWords, word)
+	if len(node.correctWords) == 0 {
+		node.isWord = false
+		node.correctWords = nil
+	}
+	node.refreshTopWords(word)
+	for _, parent := range path[1 : len(path)-1] {
+		parent.possibleData.remove(doc)
+		decrementWord(parent.possibleWords, word)
+		parent.refreshTopWords(word)
+	}
+	for i := len(path) - 1; i > 0; i-- {
+		node, parent := path[i], path[i-1]
+		runeValue, _ := utf8.DecodeRuneInString(node.label(parent))
+		switch {
+		case node.isWord:
+		case len(node.children) == 0:
+			delete(parent.children, runeValue)
+		case len(node.children) == 1:
+			for _, child := range node.children {
+				parent.children[runeValue] = child
+			}
+		}
+	}
+}
+
+// decrementWord decreases the count of the word and deletes it when it is zero
+func decrementWord(words map[string]int, word string) {
+	if words[word] > 1 {
+		words[word]--
+		return
+	}
+	delete(words, word)
+}
+
+// refreshTopWords computes again the best completions of the node if the word was one of them
+// as its count decreased, a word that was not in the list may take its place
+func (t *Node) refreshTopWords(word string) {
+	for _, top := range t.topWords {
+		if top.word != word {
+			continue
+		}
+		t.topWords = nil
+		for _, words := range []map[string]int{t.correctWords, t.possibleWords} {
+			for word, count := range words {
+				t.updateTopWords(word, count)
+			}
+		}
+		return
+	}
+}
+
+// remove forgets the ID of the document, the ordinal is not used again so the postings of other documents do not change
+func (d *documentTable) remove(doc uint32) {
+	delete(d.ordinals, d.ids[doc])
+	d.ids[doc], d.names[doc], d.words[doc] = "", "", ""
+}
@@ -0,0 +1,189 @@
+package trie
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// nodePrefixes returns the prefix of every node under the node, so two tries with the same prefixes have the same shape
+func nodePrefixes(t *Node) []string {
+	var prefixes []string
+	for _, child := range t.children {
+		prefixes = append(prefixes, child.currentWord)
+		prefixes = append(prefixes, nodePrefixes(child)...)
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+func Test_Remove(t *testing.T) {
+	cases := map[string]struct {
+		build    func() *Node
+		expected func() *Node
+	}{
+		"Documents added after": {
+			build: func() *Node {
+				trieNode := indexTrie()
+				trieNode.Add("8", "Direito Penal Especial")
+				trieNode.Add("9", "Dirigível Antigo")
+				trieNode.Add("10", "Direito-Penal", "-")
+				trieNode.Remove("9")
+				trieNode.Remove("8")
+				trieNode.Remove("10")
+				return trieNode
+			},
+			expected: indexTrie,
+		},
+		"Document in the middle": {
+			build: func() *Node {
+				trieNode := indexTrie()
+				trieNode.Add("8", "Direito Tributário")
+				trieNode.Remove("4")
+				return trieNode
+			},
+			expected: func() *Node {
+				return addDocuments(append(append(indexDocs[:3:3], indexDocs[4:]...), Document{"8", "Direito Tributário"}))
+			},
+		},
+		"Added again with another name": {
+			build: func() *Node {
+				trieNode := indexTrie()
+				trieNode.Add("8", "Direito Tributário")
+				trieNode.Add("8", "Processo-Civil", "-")
+				trieNode.Remove("8")
+				return trieNode
+			},
+			expected: indexTrie,
+		},
+		"Added again": {
+			build: func() *Node {
+				trieNode := indexTrie()
+				trieNode.Remove("3")
+				trieNode.Add("3", "Direção Defensiva")
+				return trieNode
+			},
+			expected: func() *Node {
+				trieNode := indexTrie()
+				trieNode.Remove("3")
+				trieNode.Add("3", "Direção Defensiva")
+				return trieNode
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			diff := cmp.Diff(nodePrefixes(tc.expected()), nodePrefixes(tc.build()))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			diff = cmp.Diff(nodePrefixes(tc.expected().suffixes), nodePrefixes(tc.build().suffixes))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			for method, search := range searcherCases {
+				diff := cmp.Diff(search(tc.expected()), search(tc.build()))
+				if diff != "" {
+					t.Fatalf("%s: %s", method, diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_RemoveEverything(t *testing.T) {
+	trieNode := indexTrie()
+	if trieNode.Remove("99") {
+		t.Fatalf("\nExpected: %v\nGot: %v", false, true)
+	}
+	for _, id := range []string{"1", "2", "3", "4", "5", "6", "7"} {
+		if !trieNode.Remove(id) {
+			t.Fatalf("\nExpected: %v\nGot: %v", true, false)
+		}
+	}
+	if trieNode.IsFilled() || len(trieNode.suffixes.children) > 0 {
+		t.Fatalf("\nExpected: %v\nGot: %v", nil, nodePrefixes(trieNode))
+	}
+	if trieNode.Remove("1") {
+		t.Fatalf("\nExpected: %v\nGot: %v", false, true)
+	}
+}
+
+func Test_RemoveAfterReadNode(t *testing.T) {
+	trieNode := indexTrie()
+	trieNode.Add("8", "Direito-Tributário", "-")
+	var buffer bytes.Buffer
+	if err := trieNode.WriteIndex(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadNode(&buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !read.Remove("8") {
+		t.Fatalf("\nExpected: %v\nGot: %v", true, false)
+	}
+	diff := cmp.Diff(nodePrefixes(indexTrie()), nodePrefixes(read))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	for method, search := range searcherCases {
+		diff := cmp.Diff(search(indexTrie()), search(read))
+		if diff != "" {
+			t.Fatalf("%s: %s", method, diff)
+		}
+	}
+}
+
+func Test_ReadNode(t *testing.T) {
+	cases := map[string]struct {
+		build func() *Node
+	}{
+		"Trie":             {indexTrie},
+		"Removed document": {func() *Node { trieNode := indexTrie(); trieNode.Remove("3"); return trieNode }},
+		"Empty trie":       {NewNode},
+		"Added again":      {addedAgainTrie},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			read := func() *Node {
+				var buffer bytes.Buffer
+				if err := tc.build().WriteIndex(&buffer); err != nil {
+					t.Fatal(err)
+				}
+				trieNode, err := ReadNode(&buffer)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return trieNode
+			}
+			diff := cmp.Diff(nodePrefixes(tc.build()), nodePrefixes(read()))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			for method, search := range searcherCases {
+				diff := cmp.Diff(search(tc.build()), search(read()))
+				if diff != "" {
+					t.Fatalf("%s: %s", method, diff)
+				}
+			}
+			changed, expected := read(), tc.build()
+			for _, trieNode := range []*Node{changed, expected} {
+				trieNode.Add("8", "Direito Tributário")
+				trieNode.Remove("1")
+			}
+			diff = cmp.Diff(expected.SearchByRelevance("direito"), changed.SearchByRelevance("direito"))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+
+	if _, err := ReadNode(bytes.NewReader([]byte("not an index"))); err != ErrInvalidIndex {
+		t.Fatalf("\nExpected: %v\nGot: %v", ErrInvalidIndex, err)
+	}
+}
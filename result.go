@@ -0,0 +1,143 @@
+package trie
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"time"
+)
+
+// queryTerm is a word of the phrase as it was found, it is exact when the documents of the word are used and not the ones of the longer words
+type queryTerm struct {
+	word  string
+	exact bool
+	// each visits the original words and the documents of every word that starts with the term
+	each func(visit func(words map[string]int, p *postings))
+}
+
+// searchResult orders the documents of the postings and returns the hits selected by the options
+// when there is a limit only the documents returned are ordered
+func searchResult(ctx context.Context, start time.Time, source documentSource, terms []queryTerm, p *postings, opts SearchOptions) (SearchResult, error) {
+	result := SearchResult{}
+	if p != nil {
+		result.Total = p.len()
+	}
+	if err := checkContext(ctx); err != nil {
+		return SearchResult{}, err
+	}
+	values := relevanceValues(source, p)
+	switch {
+	case opts.Pagination != nil:
+		values, result.Pagination = paginateValues(values, *opts.Pagination)
+	case opts.Limit > 0 && opts.Limit < result.Total:
+		values = firstValues(values, opts.Limit)
+	default:
+		sort.Sort(values)
+	}
+	result.Hits = newHits(values, terms)
+	if opts.Facets > 0 && result.Total > 0 {
+		result.Facets = facets(terms, p, opts.Facets)
+	}
+	result.Took = time.Since(start)
+	return result, nil
+}
+
+// firstValues returns the limit most relevant values in order, only the values returned are ordered
+func firstValues(values byRelevance, limit int) byRelevance {
+	heap.Init(&values)
+	first := make(byRelevance, 0, limit)
+	for len(first) < limit && len(values) > 0 {
+		first = append(first, heap.Pop(&values).(*internalOrderData))
+	}
+	return first
+}
+
+// newHits returns the hits of the ordered values, every document found matched all the terms
+// the terms are shared by the hits and the positions are copied in a single block, so the postings of the trie are not exposed
+func newHits(values byRelevance, terms []queryTerm) []Hit {
+	if len(values) == 0 {
+		return nil
+	}
+	words := make([]string, len(terms))
+	matchType, quality := MatchExact, 0.0
+	for i, term := range terms {
+		words[i] = term.word
+		if term.exact {
+			quality++
+		} else {
+			quality += 0.5
+			matchType = MatchPrefix
+		}
+	}
+	if len(terms) > 0 {
+		quality /= float64(len(terms))
+	}
+	size := 0
+	for _, value := range values {
+		size += len(value.position)
+	}
+	positions := make([]int, 0, size)
+	hits := make([]Hit, len(values))
+	for i, value := range values {
+		first := 0
+		if len(value.position) > 0 {
+			first = value.position[0]
+		}
+		start := len(positions)
+		positions = append(positions, value.position...)
+		hits[i] = Hit{
+			ID:        value.id,
+			Name:      value.name,
+			Score:     quality / float64(first+1),
+			Terms:     words[:len(words):len(words)],
+			Positions: positions[start:len(positions):len(positions)],
+			MatchType: matchType,
+		}
+	}
+	return hits
+}
+
+// facets counts the documents of the postings that have every word that completes a term that is a prefix
+// only the k words with more documents are returned for every term
+func facets(terms []queryTerm, p *postings, k int) map[string][]FacetCount {
+	var found map[string][]FacetCount
+	for _, term := range terms {
+		if term.exact {
+			continue
+		}
+		counts := make(map[string]int)
+		term.each(func(words map[string]int, data *postings) {
+			countPhrase(counts, "", words, data, p)
+		})
+		facet := facetCounts(counts, k)
+		if facet == nil {
+			continue
+		}
+		if found == nil {
+			found = make(map[string][]FacetCount)
+		}
+		found[term.word] = facet
+	}
+	return found
+}
+
+// facetCounts returns the k words with more documents and their counts
+func facetCounts(counts map[string]int, k int) []FacetCount {
+	var facet []FacetCount
+	for _, word := range selectTopWords(k, counts) {
+		facet = append(facet, FacetCount{Word: word, Count: counts[word]})
+	}
+	return facet
+}
+
+// searchData returns the ID and name of the hits, as returned by the methods before SearchContext
+func (r SearchResult) searchData() []SearchData {
+	if len(r.Hits) == 0 {
+		return nil
+	}
+	data := make([]SearchData, 0, len(r.Hits))
+	for _, hit := range r.Hits {
+		data = append(data, SearchData{ID: hit.ID, Name: hit.Name})
+	}
+	return data
+}
@@ -0,0 +1,93 @@
+package trie
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_SearchResult(t *testing.T) {
+	cases := map[string]struct {
+		phrase   string
+		opts     SearchOptions
+		expected SearchResult
+	}{
+		"Exact terms": {"direito penal", SearchOptions{}, SearchResult{
+			Hits: []Hit{
+				{ID: "1", Name: "Direito Penal", Score: 1, Terms: []string{"direito", "penal"}, Positions: []int{0, 1}, MatchType: MatchExact},
+				{ID: "7", Name: "Direito Penal Militar", Score: 1, Terms: []string{"direito", "penal"}, Positions: []int{0, 1}, MatchType: MatchExact},
+			},
+			Total: 2,
+		}},
+		"Prefix term with facets": {"administ", SearchOptions{Facets: 5}, SearchResult{
+			Hits: []Hit{
+				{ID: "4", Name: "Administração Pública", Score: 0.5, Terms: []string{"administ"}, Positions: []int{0}, MatchType: MatchPrefix},
+				{ID: "5", Name: "Direito Administrativo", Score: 0.25, Terms: []string{"administ"}, Positions: []int{1}, MatchType: MatchPrefix},
+			},
+			Total:  2,
+			Facets: map[string][]FacetCount{"administ": {{Word: "Administrativo", Count: 1}, {Word: "Administração", Count: 1}}},
+		}},
+		"Exact and prefix terms": {"penal dire", SearchOptions{Facets: 5}, SearchResult{
+			Hits: []Hit{
+				{ID: "1", Name: "Direito Penal", Score: 0.75, Terms: []string{"penal", "dire"}, Positions: []int{0, 1}, MatchType: MatchPrefix},
+				{ID: "7", Name: "Direito Penal Militar", Score: 0.75, Terms: []string{"penal", "dire"}, Positions: []int{0, 1}, MatchType: MatchPrefix},
+			},
+			Total:  2,
+			Facets: map[string][]FacetCount{"dire": {{Word: "Direito", Count: 2}}},
+		}},
+		"Facets limited": {"dire", SearchOptions{Limit: 1, Facets: 1}, SearchResult{
+			Hits:   []Hit{{ID: "2", Name: "Direito Civil", Score: 0.5, Terms: []string{"dire"}, Positions: []int{0}, MatchType: MatchPrefix}},
+			Total:  5,
+			Facets: map[string][]FacetCount{"dire": {{Word: "Direito", Count: 4}}},
+		}},
+		"Pagination": {"dire", SearchOptions{Limit: 1, Pagination: &Pagination{PerPage: 2, Page: 2}}, SearchResult{
+			Hits: []Hit{
+				{ID: "3", Name: "Direção Defensiva", Score: 0.5, Terms: []string{"dire"}, Positions: []int{0}, MatchType: MatchPrefix},
+				{ID: "7", Name: "Direito Penal Militar", Score: 0.5, Terms: []string{"dire"}, Positions: []int{0}, MatchType: MatchPrefix},
+			},
+			Total:      5,
+			Pagination: Pagination{PerPage: 2, Page: 2, Total: 5},
+		}},
+		"Nothing found": {"xyz", SearchOptions{Facets: 5}, SearchResult{}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, searcher := range []SearcherInterface{indexTrie(), loadedIndex(t)} {
+				result, err := searcher.SearchContext(context.Background(), tc.phrase, tc.opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				diff := cmp.Diff(tc.expected, result, cmpopts.IgnoreFields(SearchResult{}, "Took"))
+				if diff != "" {
+					t.Fatalf(diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_SearchResultWrappers(t *testing.T) {
+	trieNode := indexTrie()
+	result, err := trieNode.SearchContext(context.Background(), "dire", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Took <= 0 {
+		t.Fatalf("\nExpected: %v\nGot: %v", "a positive duration", result.Took)
+	}
+	diff := cmp.Diff(trieNode.SearchByRelevance("dire"), result.searchData())
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	data, pagination := trieNode.SearchByRelevancePaginated("dire", Pagination{PerPage: 2, Page: 1})
+	diff = cmp.Diff(result.searchData()[:2], data)
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+	if pagination.Total != int32(result.Total) {
+		t.Fatalf("\nExpected: %v\nGot: %v", result.Total, pagination.Total)
+	}
+}
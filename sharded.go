@@ -0,0 +1,744 @@
+package trie
+
+import (
+	"container/heap"
+	"context"
+	"hash/fnv"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shardTerm is a word of the phrase resolved in every shard, the prefix is the longest one found in any shard
+// it is exact when a word ends in the prefix in any shard and found when any shard has documents in the prefix
+type shardTerm struct {
+	word    string
+	cleaned string
+	prefix  string
+	exact   bool
+	found   bool
+}
+
+// shardResult is the part of a search done by one shard, the values are the ones the page may need, already ordered
+// after is the number of values after the cursor and facets has the words of every term that is a prefix
+type shardResult struct {
+	total  int
+	values byRelevance
+	after  int
+	facets []wordTotals
+	err    error
+}
+
+// wordTotals sums the original words and the documents of the cleaned words found in many shards
+// so the words are counted as they would be in a single trie
+type wordTotals map[string]*wordTotal
+
+type wordTotal struct {
+	words map[string]int
+	count int
+}
+
+// shardWord is a word visited by WalkPrefix in a shard, the original words are copied so they are read without the lock
+type shardWord struct {
+	cleaned string
+	words   map[string]int
+	ids     []string
+}
+
+// NewShardedIndex returns an empty index with the number of shards, zero uses one for every processor
+func NewShardedIndex(shards int) *ShardedIndex {
+	if shards <= 0 {
+		shards = runtime.GOMAXPROCS(0)
+	}
+	s := &ShardedIndex{shards: make([]*Node, shards), locks: make([]sync.Mutex, shards)}
+	for i := range s.shards {
+		s.shards[i] = NewNode()
+	}
+	return s
+}
+
+// shardOf returns the shard of the document ID
+func (s *ShardedIndex) shardOf(id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % uint32(len(s.shards)))
+}
+
+// each calls f for every shard in order while the shard is locked
+func (s *ShardedIndex) each(f func(i int, t *Node)) {
+	for i, t := range s.shards {
+		s.locks[i].Lock()
+		f(i, t)
+		s.locks[i].Unlock()
+	}
+}
+
+// fanOut calls f for every shard in its own goroutine while the shard is locked, and waits until every call returns
+func (s *ShardedIndex) fanOut(f func(i int, t *Node)) {
+	var wg sync.WaitGroup
+	wg.Add(len(s.shards))
+	for i, t := range s.shards {
+		go func(i int, t *Node) {
+			defer wg.Done()
+			s.locks[i].Lock()
+			defer s.locks[i].Unlock()
+			f(i, t)
+		}(i, t)
+	}
+	wg.Wait()
+}
+
+// Add inserts the document in the shard of its ID, only that shard is locked
+func (s *ShardedIndex) Add(id, name string, remove ...string) {
+	i := s.shardOf(id)
+	s.locks[i].Lock()
+	defer s.locks[i].Unlock()
+	s.shards[i].Add(id, name, remove...)
+}
+
+// AddBatch splits the documents by shard and adds them to every shard at the same time
+func (s *ShardedIndex) AddBatch(docs []Document, remove ...string) {
+	batches := make([][]Document, len(s.shards))
+	for _, doc := range docs {
+		i := s.shardOf(doc.ID)
+		batches[i] = append(batches[i], doc)
+	}
+	s.fanOut(func(i int, t *Node) {
+		if len(batches[i]) > 0 {
+			t.AddBatch(batches[i], remove...)
+		}
+	})
+}
+
+// Remove takes the document out of the shard of its ID, it returns false if the ID is not in the index
+func (s *ShardedIndex) Remove(id string) bool {
+	i := s.shardOf(id)
+	s.locks[i].Lock()
+	defer s.locks[i].Unlock()
+	return s.shards[i].Remove(id)
+}
+
+// IsFilled return true if any shard has a document
+func (s *ShardedIndex) IsFilled() bool {
+	filled := false
+	s.each(func(_ int, t *Node) {
+		filled = filled || t.IsFilled()
+	})
+	return filled
+}
+
+// HasWord return true if the word is in any shard
+func (s *ShardedIndex) HasWord(word string) bool {
+	found := false
+	s.each(func(_ int, t *Node) {
+		found = found || t.HasWord(word)
+	})
+	return found
+}
+
+// GetPossibleWords return the possible words of the word in every shard, ordered by the times they were inserted in all of them
+func (s *ShardedIndex) GetPossibleWords(word string) []string {
+	cleanedString := cleanString(word)
+	var words map[string]int
+	s.each(func(_ int, t *Node) {
+		node := t.getNode(cleanedString)
+		if node == nil {
+			return
+		}
+		if words == nil {
+			words = make(map[string]int)
+		}
+		for possible, count := range node.possibleWords {
+			words[possible] += count
+		}
+	})
+	if words == nil {
+		return nil
+	}
+	return getKeyListOrderedFromMap(words)
+}
+
+// Complete return the k words most inserted that start with the prefix, the counts of every shard are added
+// so every word of the prefix is read, the lists of best completions of the nodes are not enough
+func (s *ShardedIndex) Complete(prefix string, k int) []string {
+	if k <= 0 {
+		return nil
+	}
+	cleanedString := cleanString(prefix)
+	words := make(map[string]int)
+	s.each(func(_ int, t *Node) {
+		node := t.getNode(cleanedString)
+		if node == nil {
+			return
+		}
+		for _, wordMap := range []map[string]int{node.correctWords, node.possibleWords} {
+			for word, count := range wordMap {
+				words[word] += count
+			}
+		}
+	})
+	return selectTopWords(k, words)
+}
+
+// AutocompletePhrase return the best documents and phrase suggestions of every shard for a phrase that is still being typed
+// the complete words and the last word are looked up in every shard, so a word in any shard is enough
+func (s *ShardedIndex) AutocompletePhrase(input string, k int) PhraseCompletion {
+	words := strings.Fields(input)
+	if k <= 0 || len(words) == 0 {
+		return PhraseCompletion{}
+	}
+	var complete []string
+	for _, word := range words[:len(words)-1] {
+		cleanedString := cleanString(word)
+		if len(cleanedString) >= minWordSize {
+			complete = append(complete, cleanedString)
+		}
+	}
+	lastWord := cleanString(words[len(words)-1])
+	if lastWord == "" {
+		return PhraseCompletion{}
+	}
+	isWord := make([]bool, len(complete))
+	found := false
+	s.each(func(_ int, t *Node) {
+		for i, word := range complete {
+			if node := t.getNode(word); node != nil && node.isWord {
+				isWord[i] = true
+			}
+		}
+		found = found || t.getNode(lastWord) != nil
+	})
+	for _, ok := range isWord {
+		if !ok {
+			return PhraseCompletion{}
+		}
+	}
+	if !found {
+		return PhraseCompletion{}
+	}
+	lists := make([]byRelevance, len(s.shards))
+	totals := make([]wordTotals, len(s.shards))
+	s.fanOut(func(i int, t *Node) {
+		var postingList []*postings
+		for _, word := range complete {
+			postingList = append(postingList, correctPostings(t.getNode(word)))
+		}
+		exact := intersectPostingList(postingList)
+		prefix := &postings{}
+		totals[i] = wordTotals{}
+		if node := t.getNode(lastWord); node != nil {
+			prefix = unionPostings(&node.correctData, &node.possibleData)
+			totals[i].add(node, exact)
+		}
+		values := relevanceValues(t.documents, intersectPostingList([]*postings{exact, prefix}))
+		lists[i] = orderValues(values, k)
+	})
+	documents := mergeValues(lists)
+	if len(documents) > k {
+		documents = documents[:k]
+	}
+	for _, shardTotals := range totals[1:] {
+		totals[0].merge(shardTotals)
+	}
+	return PhraseCompletion{
+		Documents: documents.searchData(),
+		Phrases:   selectTopWords(k, totals[0].counts(strings.Join(words[:len(words)-1], " "))),
+	}
+}
+
+// correctPostings returns a copy of the postings of the words that end in the node, a missing node has no documents
+func correctPostings(node *Node) *postings {
+	if node == nil || !node.isWord {
+		return &postings{}
+	}
+	return unionPostingList([]*postings{&node.correctData})
+}
+
+// SearchByRelevance return the documents of every shard in the same order of a single trie with the same documents
+func (s *ShardedIndex) SearchByRelevance(phrase string) []SearchData {
+	result, _ := s.SearchContext(context.Background(), phrase, SearchOptions{})
+	return result.searchData()
+}
+
+// SearchByRelevancePaginated return the page of the documents of every shard, the total is the sum of the totals of the shards
+func (s *ShardedIndex) SearchByRelevancePaginated(phrase string, pagination Pagination) ([]SearchData, Pagination) {
+	result, _ := s.SearchContext(context.Background(), phrase, SearchOptions{Pagination: &pagination})
+	return result.searchData(), result.Pagination
+}
+
+// SearchContext searches every shard concurrently and merges the hits
+// every shard orders only the hits the page or the limit may need, so the merged hits are the first ones of all the shards
+func (s *ShardedIndex) SearchContext(ctx context.Context, phrase string, opts SearchOptions) (SearchResult, error) {
+	start := time.Now()
+	terms := s.resolve(phrase)
+	var cursor *internalOrderData
+	valid := true
+	if opts.Pagination != nil && opts.Pagination.Mode == CursorMode && opts.Pagination.Cursor != "" {
+		cursor, valid = decodeCursor(opts.Pagination.Cursor)
+	}
+	if err := s.checkCandidates(ctx, terms, opts.MaxCandidates); err != nil {
+		return SearchResult{}, err
+	}
+	results := make([]shardResult, len(s.shards))
+	s.fanOut(func(i int, t *Node) {
+		results[i] = t.searchShard(ctx, terms, opts, cursor)
+	})
+	result := SearchResult{}
+	lists := make([]byRelevance, len(results))
+	after := 0
+	for i, r := range results {
+		if r.err != nil {
+			return SearchResult{}, r.err
+		}
+		result.Total += r.total
+		lists[i] = r.values
+		after += r.after
+	}
+	if err := checkContext(ctx); err != nil {
+		return SearchResult{}, err
+	}
+	values := mergeValues(lists)
+	switch {
+	case opts.Pagination != nil:
+		values, result.Pagination = paginateShards(values, *opts.Pagination, result.Total, after, valid)
+	case opts.Limit > 0 && opts.Limit < len(values):
+		values = values[:opts.Limit]
+	}
+	result.Hits = newHits(values, shardQueryTerms(terms))
+	if opts.Facets > 0 && result.Total > 0 {
+		result.Facets = shardFacets(terms, results, opts.Facets)
+	}
+	result.Took = time.Since(start)
+	return result, nil
+}
+
+// resolve finds the prefix of every word of the phrase, the deepest node of a word is the deepest one of all the shards
+// as it is the node a single trie with every document would have
+func (s *ShardedIndex) resolve(phrase string) []shardTerm {
+	var terms []shardTerm
+	for _, word := range strings.Fields(phrase) {
+		cleanedString := cleanString(word)
+		if len(cleanedString) < minWordSize {
+			continue
+		}
+		terms = append(terms, shardTerm{word: word, cleaned: cleanedString})
+	}
+	s.each(func(_ int, t *Node) {
+		for i := range terms {
+			if node := t.getDeepestNode(terms[i].cleaned); len(node.currentWord) > len(terms[i].prefix) {
+				terms[i].prefix = node.currentWord
+			}
+		}
+	})
+	s.each(func(_ int, t *Node) {
+		for i := range terms {
+			node := t.getNode(terms[i].prefix)
+			if node == nil {
+				continue
+			}
+			terms[i].exact = terms[i].exact || node.correctData.len() > 0
+			terms[i].found = terms[i].found || node.correctData.len() > 0 || node.possibleData.len() > 0
+		}
+	})
+	return terms
+}
+
+// shardQueryTerms returns the terms found in any shard, in the same way nodeTerms returns the terms of a trie
+func shardQueryTerms(terms []shardTerm) []queryTerm {
+	var found []queryTerm
+	for _, term := range terms {
+		if term.found {
+			found = append(found, queryTerm{word: term.prefix, exact: term.exact})
+		}
+	}
+	return found
+}
+
+// termPostings returns the postings of the terms in the shard, the correct ones when the term is exact in any shard
+// a term that is not found in any shard is nil, as in a single trie, and a term only found in other shards has no documents
+func (t *Node) termPostings(terms []shardTerm) []*postings {
+	postingList := make([]*postings, len(terms))
+	for i, term := range terms {
+		if !term.found {
+			continue
+		}
+		node := t.getNode(term.prefix)
+		switch {
+		case node == nil:
+			postingList[i] = &postings{}
+		case term.exact:
+			postingList[i] = &node.correctData
+		default:
+			postingList[i] = &node.possibleData
+		}
+	}
+	return postingList
+}
+
+// checkCandidates returns ErrTooManyCandidates when the term with the fewest documents of all the shards has more than the maximum
+// the documents of every term are counted before any shard intersects them, zero has no maximum
+func (s *ShardedIndex) checkCandidates(ctx context.Context, terms []shardTerm, maxCandidates int) error {
+	if err := checkContext(ctx); err != nil || maxCandidates <= 0 {
+		return err
+	}
+	sizes := make([]int, len(terms))
+	for i, term := range terms {
+		if !term.found {
+			sizes[i] = -1
+		}
+	}
+	s.each(func(_ int, t *Node) {
+		for i, p := range t.termPostings(terms) {
+			if p != nil {
+				sizes[i] += p.len()
+			}
+		}
+	})
+	if size := fewest(sizes); size > maxCandidates {
+		return candidatesError{candidates: size}
+	}
+	return nil
+}
+
+// searchShard intersects the terms in the shard and returns the values needed by the options
+func (t *Node) searchShard(ctx context.Context, terms []shardTerm, opts SearchOptions, cursor *internalOrderData) shardResult {
+	p, err := intersectListContext(ctx, t.termPostings(terms))
+	if err != nil {
+		return shardResult{err: err}
+	}
+	r := shardResult{}
+	if p != nil {
+		r.total = p.len()
+	}
+	if err := checkContext(ctx); err != nil {
+		return shardResult{err: err}
+	}
+	values := relevanceValues(t.documents, p)
+	switch pagination := opts.Pagination; {
+	case pagination != nil && pagination.Mode == CursorMode:
+		sort.Sort(values)
+		if cursor != nil {
+			values = values[sort.Search(len(values), func(i int) bool {
+				return cursor.before(values[i])
+			}):]
+		}
+		r.after = len(values)
+		if pagination.PerPage >= 0 && int(pagination.PerPage) < len(values) {
+			values = values[:pagination.PerPage]
+		}
+		r.values = values
+	case pagination != nil && pagination.PerPage >= 0:
+		// one more value than the page is kept, so an empty page is only returned when the page is after the last value
+		r.values = orderValues(values, pagination.Offset()+int(pagination.PerPage)+1)
+	case pagination != nil:
+		r.values = orderValues(values, 0)
+	default:
+		r.values = orderValues(values, opts.Limit)
+	}
+	if opts.Facets > 0 && r.total > 0 {
+		r.facets = make([]wordTotals, len(terms))
+		for i, term := range terms {
+			if !term.found || term.exact {
+				continue
+			}
+			r.facets[i] = wordTotals{}
+			if node := t.getNode(term.prefix); node != nil {
+				r.facets[i].add(node, p)
+			}
+		}
+	}
+	return r
+}
+
+// orderValues returns the limit most relevant values in order, zero orders every value
+func orderValues(values byRelevance, limit int) byRelevance {
+	if limit > 0 && limit < len(values) {
+		return firstValues(values, limit)
+	}
+	sort.Sort(values)
+	return values
+}
+
+// mergeValues merges the ordered values of every shard in a single ordered list
+func mergeValues(lists []byRelevance) byRelevance {
+	size := 0
+	for _, list := range lists {
+		size += len(list)
+	}
+	merged := make(byRelevance, 0, size)
+	for {
+		best := -1
+		for i, list := range lists {
+			if len(list) > 0 && (best < 0 || list[0].before(lists[best][0])) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return merged
+		}
+		merged = append(merged, lists[best][0])
+		lists[best] = lists[best][1:]
+	}
+}
+
+// paginateShards returns the page of the merged values, the total and the values after the cursor are the sums of every shard
+// it returns the same pagination paginateValues returns for a single trie
+func paginateShards(values byRelevance, pagination Pagination, total, after int, valid bool) (byRelevance, Pagination) {
+	if pagination.Mode != CursorMode {
+		page, paginated := paginateList(values, pagination)
+		if len(values) > 0 && pagination.Offset() < len(values) {
+			paginated.Total = int32(total)
+		}
+		return page, paginated
+	}
+	pagination.NextCursor = ""
+	if !valid {
+		return nil, pagination
+	}
+	end := len(values)
+	if pagination.PerPage >= 0 && int(pagination.PerPage) < end {
+		end = int(pagination.PerPage)
+	}
+	if end < after && end > 0 {
+		pagination.NextCursor = encodeCursor(values[end-1])
+	}
+	pagination.Total = int32(total)
+	return values[:end], pagination
+}
+
+// shardFacets adds the words of every term that is a prefix found by the shards and returns the k words with more documents
+func shardFacets(terms []shardTerm, results []shardResult, k int) map[string][]FacetCount {
+	var found map[string][]FacetCount
+	for i, term := range terms {
+		if !term.found || term.exact {
+			continue
+		}
+		totals := wordTotals{}
+		for _, r := range results {
+			if r.facets != nil {
+				totals.merge(r.facets[i])
+			}
+		}
+		facet := facetCounts(totals.counts(""), k)
+		if facet == nil {
+			continue
+		}
+		if found == nil {
+			found = make(map[string][]FacetCount)
+		}
+		found[term.prefix] = facet
+	}
+	return found
+}
+
+// add counts the words under the node, only the documents also in the exact postings are counted if there is any
+func (w wordTotals) add(node *Node, exact *postings) {
+	node.walkWords(func(word *Node) {
+		count := word.correctData.len()
+		if exact != nil {
+			count = and(&exact.docs, &word.correctData.docs).cardinality()
+		}
+		w.addWords(word.currentWord, word.correctWords, count)
+	})
+}
+
+func (w wordTotals) addWords(cleaned string, words map[string]int, count int) {
+	total, ok := w[cleaned]
+	if !ok {
+		total = &wordTotal{words: make(map[string]int)}
+		w[cleaned] = total
+	}
+	for word, inserted := range words {
+		total.words[word] += inserted
+	}
+	total.count += count
+}
+
+// merge adds the words of the other totals
+func (w wordTotals) merge(other wordTotals) {
+	for cleaned, total := range other {
+		w.addWords(cleaned, total.words, total.count)
+	}
+}
+
+// counts returns the number of documents of every word with documents, the phrase is completed in the same way as countPhrase
+func (w wordTotals) counts(phrase string) map[string]int {
+	counts := make(map[string]int)
+	for _, total := range w {
+		if total.count > 0 {
+			counts[strings.TrimSpace(phrase+" "+mostInserted(total.words))] = total.count
+		}
+	}
+	return counts
+}
+
+// Explain return the terms of the phrase and the keys that rank the document of the ID among the documents of every shard
+// the documents of the terms and the rank are counted in every shard, the positions are read in the shard of the ID
+func (s *ShardedIndex) Explain(phrase, id string) Explanation {
+	terms := s.resolve(phrase)
+	explanation := Explanation{ID: id}
+	for _, term := range terms {
+		matchType := MatchPrefix
+		if term.exact {
+			matchType = MatchExact
+		}
+		explanation.Terms = append(explanation.Terms, TermExplanation{Word: term.word, Node: term.prefix, MatchType: matchType})
+	}
+	home := s.shardOf(id)
+	order := []int{home}
+	for i := range s.shards {
+		if i != home {
+			order = append(order, i)
+		}
+	}
+	var value *internalOrderData
+	total, rank := 0, 1
+	for _, i := range order {
+		s.locks[i].Lock()
+		t := s.shards[i]
+		doc, known := t.documents.ordinals[id]
+		known = known && i == home
+		if known {
+			_, explanation.Name = t.documents.document(doc)
+		}
+		postingList := t.termPostings(terms)
+		for j, p := range postingList {
+			if p == nil {
+				continue
+			}
+			explanation.Terms[j].Documents += p.len()
+			if known && p.docs.contains(doc) {
+				explanation.Terms[j].Matched = true
+				explanation.Terms[j].Positions = append([]int{}, p.positions[p.docs.rank(doc)-1]...)
+			}
+		}
+		p := intersectPostingList(postingList)
+		if p == nil {
+			s.locks[i].Unlock()
+			return explanation
+		}
+		total += p.len()
+		values := relevanceValues(t.documents, p)
+		if known && p.docs.contains(doc) {
+			value = values[p.docs.rank(doc)-1]
+		}
+		if value != nil {
+			for _, other := range values {
+				if other.before(value) {
+					rank++
+				}
+			}
+		}
+		s.locks[i].Unlock()
+	}
+	explanation.Total = total
+	if value == nil {
+		return explanation
+	}
+	hit := newHits(byRelevance{value}, shardQueryTerms(terms))[0]
+	explanation.Found = true
+	explanation.Rank = rank
+	explanation.Positions = hit.Positions
+	explanation.NameSize = len(value.name)
+	explanation.Score = hit.Score
+	explanation.MatchType = hit.MatchType
+	return explanation
+}
+
+// IterSearch return an iterator over the same results of SearchByRelevance, the documents of every shard are ordered while they are read
+func (s *ShardedIndex) IterSearch(phrase string) Iterator {
+	terms := s.resolve(phrase)
+	it := &relevanceIterator{}
+	s.each(func(_ int, t *Node) {
+		p := intersectPostingList(t.termPostings(terms))
+		it.values = append(it.values, relevanceValues(t.documents, p)...)
+	})
+	heap.Init(&it.values)
+	return it
+}
+
+// WalkPrefix visits the words of every shard that start with the prefix in increasing order
+// the IDs of a word are the ones of every shard, one shard after the other, and the words of every shard are read before the first visit
+func (s *ShardedIndex) WalkPrefix(prefix string, visit func(word string, ids []string) bool) {
+	cleanedString := cleanString(prefix)
+	lists := make([][]shardWord, len(s.shards))
+	s.each(func(i int, t *Node) {
+		node := t.getNode(cleanedString)
+		if node == nil {
+			return
+		}
+		node.walkSortedWords(func(word *Node) bool {
+			words := make(map[string]int, len(word.correctWords))
+			for original, count := range word.correctWords {
+				words[original] = count
+			}
+			lists[i] = append(lists[i], shardWord{cleaned: word.currentWord, words: words, ids: idList(t.documents, &word.correctData)})
+			return true
+		})
+	})
+	for {
+		next := ""
+		for _, list := range lists {
+			if len(list) > 0 && (next == "" || list[0].cleaned < next) {
+				next = list[0].cleaned
+			}
+		}
+		if next == "" {
+			return
+		}
+		totals := wordTotals{}
+		var ids []string
+		for i, list := range lists {
+			if len(list) > 0 && list[0].cleaned == next {
+				totals.addWords(next, list[0].words, 0)
+				ids = append(ids, list[0].ids...)
+				lists[i] = list[1:]
+			}
+		}
+		if !visit(mostInserted(totals[next].words), ids) {
+			return
+		}
+	}
+}
+
+// SearchByRelevanceHighlighted return the same data as SearchByRelevance with the matches of the phrase in every name
+func (s *ShardedIndex) SearchByRelevanceHighlighted(phrase string, remove ...string) []HighlightedData {
+	var highlighted []HighlightedData
+	for _, data := range s.SearchByRelevance(phrase) {
+		highlighted = append(highlighted, HighlightedData{SearchData: data, Matches: FindMatches(data.Name, phrase, remove...)})
+	}
+	return highlighted
+}
+
+// SearchInfix return the documents of every shard with the fragments of the phrase anywhere inside the words
+func (s *ShardedIndex) SearchInfix(phrase string) []SearchData {
+	return s.orderShards(func(t *Node) *postings {
+		return t.searchInfixPostings(phrase)
+	})
+}
+
+// SearchPattern return the documents of every shard with words matching the wildcard patterns of the phrase
+func (s *ShardedIndex) SearchPattern(phrase string) []SearchData {
+	return s.orderShards(func(t *Node) *postings {
+		return t.searchPatternPostings(phrase)
+	})
+}
+
+// SearchRegexp return the documents of every shard with a word matched by the regexp
+func (s *ShardedIndex) SearchRegexp(re *regexp.Regexp) []SearchData {
+	return s.orderShards(func(t *Node) *postings {
+		return t.searchRegexpPostings(re)
+	})
+}
+
+// orderShards orders the postings found in every shard concurrently and merges them
+func (s *ShardedIndex) orderShards(search func(t *Node) *postings) []SearchData {
+	lists := make([]byRelevance, len(s.shards))
+	s.fanOut(func(i int, t *Node) {
+		lists[i] = orderValues(relevanceValues(t.documents, search(t)), 0)
+	})
+	return mergeValues(lists).searchData()
+}
@@ -0,0 +1,153 @@
+package trie
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// shardedIndex returns an index with the documents of indexTrie in the number of shards
+func shardedIndex(shards int) *ShardedIndex {
+	s := NewShardedIndex(shards)
+	for _, doc := range indexDocs {
+		s.Add(doc.ID, doc.Name)
+	}
+	return s
+}
+
+func Test_ShardedIndex(t *testing.T) {
+	for _, shards := range []int{1, 2, 3, 7} {
+		for name, search := range searcherCases {
+			if name == "Walk prefix" {
+				// the IDs of a word are grouped by shard, Test_ShardedIndexWalkPrefix compares them sorted
+				continue
+			}
+			t.Run(fmt.Sprint(shards, " shards ", name), func(t *testing.T) {
+				diff := cmp.Diff(search(indexTrie()), search(shardedIndex(shards)))
+				if diff != "" {
+					t.Fatalf(diff)
+				}
+			})
+		}
+	}
+}
+
+func Test_ShardedIndexWalkPrefix(t *testing.T) {
+	walk := func(s SearcherInterface) []string {
+		var words []string
+		s.WalkPrefix("", func(word string, ids []string) bool {
+			sort.Strings(ids)
+			words = append(words, fmt.Sprint(word, ids))
+			return len(words) < 5
+		})
+		return words
+	}
+	diff := cmp.Diff(walk(indexTrie()), walk(shardedIndex(3)))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+func Test_ShardedIndexSameAsTrie(t *testing.T) {
+	docs := syntheticDocuments(4000)
+	trieNode := NewNode()
+	for _, doc := range docs {
+		trieNode.Add(doc.ID, doc.Name)
+	}
+	sharded := NewShardedIndex(4)
+	sharded.AddBatch(docs)
+
+	cases := map[string]func(s SearcherInterface, prefix string) interface{}{
+		"Search": func(s SearcherInterface, prefix string) interface{} {
+			return s.SearchByRelevance(prefix + " " + prefix[:2] + "ba")
+		},
+		"Page": func(s SearcherInterface, prefix string) interface{} {
+			data, pagination := s.SearchByRelevancePaginated(prefix, Pagination{PerPage: 3, Page: 2})
+			return []interface{}{data, pagination}
+		},
+		"Every page with cursor": func(s SearcherInterface, prefix string) interface{} {
+			results, pages := readAllPages(s, prefix, 4)
+			return []interface{}{results, pages}
+		},
+		"Limit and facets": func(s SearcherInterface, prefix string) interface{} {
+			result, err := s.SearchContext(context.Background(), prefix, SearchOptions{Limit: 5, Facets: 3})
+			result.Took = 0
+			return []interface{}{result, err}
+		},
+		"Complete":      func(s SearcherInterface, prefix string) interface{} { return s.Complete(prefix, 5) },
+		"Autocomplete":  func(s SearcherInterface, prefix string) interface{} { return s.AutocompletePhrase("x "+prefix, 3) },
+		"Infix":         func(s SearcherInterface, prefix string) interface{} { return s.SearchInfix(prefix) },
+		"Explain first": func(s SearcherInterface, prefix string) interface{} { return s.Explain(prefix, "0") },
+	}
+
+	for name, search := range cases {
+		t.Run(name, func(t *testing.T) {
+			for _, first := range syllables {
+				prefix := first + "ri"
+				diff := cmp.Diff(search(trieNode, prefix), search(sharded, prefix))
+				if diff != "" {
+					t.Fatalf("%v: %v", prefix, diff)
+				}
+			}
+		})
+	}
+}
+
+func Test_ShardedIndexRemove(t *testing.T) {
+	s := shardedIndex(3)
+	if !s.Remove("5") {
+		t.Fatalf("\nExpected: %v\nGot: %v", true, false)
+	}
+	if s.Remove("5") {
+		t.Fatalf("\nExpected: %v\nGot: %v", false, true)
+	}
+	expected := indexTrie()
+	expected.Remove("5")
+	diff := cmp.Diff(expected.SearchByRelevance("admin"), s.SearchByRelevance("admin"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+func Test_ShardedIndexConcurrent(t *testing.T) {
+	var s NodeInterface = NewShardedIndex(4)
+	docs := syntheticDocuments(2000)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(docs); i += 4 {
+				s.Add(docs[i].ID, docs[i].Name)
+			}
+		}(w)
+		go func(w int) {
+			defer wg.Done()
+			for _, syllable := range syllables {
+				s.SearchByRelevancePaginated(syllable, Pagination{PerPage: 10, Page: 1})
+				s.Remove(strconv.Itoa(w))
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	trieNode := NewNode()
+	for _, doc := range docs {
+		trieNode.Add(doc.ID, doc.Name)
+	}
+	for w := 0; w < 4; w++ {
+		trieNode.Remove(strconv.Itoa(w))
+		s.Remove(strconv.Itoa(w))
+	}
+	for _, syllable := range syllables {
+		diff := cmp.Diff(trieNode.SearchByRelevance(syllable), s.SearchByRelevance(syllable))
+		if diff != "" {
+			t.Fatalf("%v: %v", syllable, diff)
+		}
+	}
+}
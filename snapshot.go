@@ -0,0 +1,99 @@
+package trie
+
+import (
+	"io"
+	"io/ioutil"
+	"unicode/utf8"
+)
+
+// ReadNode reads an index written by WriteIndex and returns a trie that can be changed again
+// so the index file is also a snapshot of the trie, the documents keep the same ordinals they had when it was written
+func ReadNode(r io.Reader) (*Node, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	index, err := LoadIndex(data)
+	if err != nil {
+		return nil, err
+	}
+	t := NewNode()
+	for doc := 0; doc < index.documents.size; doc++ {
+		id, name := index.documents.document(uint32(doc))
+		t.documents.ids = append(t.documents.ids, id)
+		t.documents.names = append(t.documents.names, name)
+		t.documents.words = append(t.documents.words, index.documents.words(uint32(doc)))
+		// a removed document keeps its ordinal without a name, as a name without words is never added
+		if name != "" {
+			t.documents.ordinals[id] = uint32(doc)
+		}
+	}
+	t.restoreSection(&index.words)
+	if index.suffixes.size > 0 {
+		t.suffixes = &Node{children: make(map[rune]*Node)}
+		t.suffixes.restoreSection(&index.suffixes)
+	}
+	return t, nil
+}
+
+// restoreSection creates the node of every word of the section and then computes the possible data of the nodes
+func (t *Node) restoreSection(s *indexSection) {
+	r := s.automaton.seek("")
+	s.automaton.each(r, func(word string, ordinal int) bool {
+		t.restoreWord(word, s.wordsOf(ordinal), s.postingsOf(ordinal))
+		return true
+	})
+	for _, child := range t.children {
+		child.restorePossible()
+	}
+}
+
+// restoreWord creates the node of the cleaned word with its original words and postings, the nodes are split as in insert
+func (t *Node) restoreWord(cleanedString string, words map[string]int, p *postings) {
+	node := t
+	for len(node.currentWord) < len(cleanedString) {
+		rest := cleanedString[len(node.currentWord):]
+		runeValue, _ := utf8.DecodeRuneInString(rest)
+		child, ok := node.children[runeValue]
+		if !ok {
+			child = &Node{currentWord: cleanedString}
+		} else if size := commonPrefixLength(child.label(node), rest); size < len(child.label(node)) {
+			prefix := cleanedString[:len(node.currentWord)+size]
+			next, _ := utf8.DecodeRuneInString(child.currentWord[len(prefix):])
+			child = &Node{currentWord: prefix, children: map[rune]*Node{next: child}}
+		}
+		if node.children == nil {
+			node.children = make(map[rune]*Node)
+		}
+		node.children[runeValue] = child
+		node = child
+	}
+	node.isWord = true
+	node.correctWords = words
+	node.correctData = *p
+}
+
+// restorePossible computes the possible words and documents of the node from the nodes under it and its best completions
+func (t *Node) restorePossible() {
+	var found []*postings
+	for _, child := range t.children {
+		child.restorePossible()
+		found = append(found, &child.correctData, &child.possibleData)
+		for _, words := range []map[string]int{child.correctWords, child.possibleWords} {
+			for word, count := range words {
+				if t.possibleWords == nil {
+					t.possibleWords = make(map[string]int)
+				}
+				t.possibleWords[word] += count
+			}
+		}
+	}
+	if len(found) > 0 {
+		t.possibleData = *unionPostingList(found)
+	}
+	for _, words := range []map[string]int{t.correctWords, t.possibleWords} {
+		for word, count := range words {
+			t.updateTopWords(word, count)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package trie
+
+import (
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Sizes in bytes used by the estimate of the heap
+const (
+	nodeSize      = int64(unsafe.Sizeof(Node{}))
+	containerSize = int64(unsafe.Sizeof(container{}))
+	wordCountSize = int64(unsafe.Sizeof(wordCount{}))
+	stringSize    = int64(unsafe.Sizeof(""))
+	sliceSize     = int64(unsafe.Sizeof([]int{}))
+	pointerSize   = int64(unsafe.Sizeof(&Node{}))
+	intSize       = int64(unsafe.Sizeof(0))
+	// mapHeaderSize is the header of a map, every entry adds its key, its value and the unused space of the buckets
+	mapHeaderSize = 48
+)
+
+// Stats return the size of the trie, the largest postings and an estimate of its memory in a single traversal
+// the largest possible postings also have the prefixes inside the edges, that have the words of the node of the edge
+func (t *Node) Stats() Stats {
+	var stats Stats
+	stats.HeapSize = nodeSize + mapSize(len(t.children), 4+pointerSize)
+	for _, child := range t.children {
+		stats.visit(t, child, 0)
+	}
+	if t.suffixes != nil {
+		stats.HeapSize += nodeSize + mapSize(len(t.suffixes.children), 4+pointerSize)
+		for _, child := range t.suffixes.children {
+			stats.visitSuffix(child)
+		}
+	}
+	if t.documents != nil {
+		stats.Documents = len(t.documents.ordinals)
+		stats.HeapSize += t.documents.heapSize()
+	}
+	return stats
+}
+
+func (s *Stats) visit(parent, t *Node, depth int) {
+	s.Nodes++
+	if len(s.Depths) == depth {
+		s.Depths = append(s.Depths, 0)
+	}
+	s.Depths[depth]++
+	if t.isWord {
+		s.Words++
+	}
+	s.Postings += t.correctData.len() + t.possibleData.len()
+	s.Positions += t.correctData.positionCount() + t.possibleData.positionCount()
+	s.HeapSize += t.heapSize()
+	s.LargestCorrect = addLargest(s.LargestCorrect, PostingSize{t.currentWord, t.correctData.len()})
+	s.LargestPossible = addLargest(s.LargestPossible, PostingSize{t.currentWord, t.possibleData.len()})
+	if label := t.label(parent); utf8.RuneCountInString(label) > 1 {
+		_, size := utf8.DecodeRuneInString(label)
+		edge := or(&t.possibleData.docs, &t.correctData.docs).cardinality()
+		s.LargestPossible = addLargest(s.LargestPossible, PostingSize{parent.currentWord + label[:size], edge})
+	}
+	for _, child := range t.children {
+		s.visit(t, child, depth+1)
+	}
+}
+
+func (s *Stats) visitSuffix(t *Node) {
+	s.SuffixNodes++
+	s.HeapSize += t.heapSize()
+	for _, child := range t.children {
+		s.visitSuffix(child)
+	}
+}
+
+// addLargest inserts the size in the list ordered by the number of IDs, the list keeps only the largest postings
+func addLargest(list []PostingSize, size PostingSize) []PostingSize {
+	if size.IDs == 0 {
+		return list
+	}
+	i := len(list)
+	for i > 0 && (list[i-1].IDs < size.IDs || (list[i-1].IDs == size.IDs && list[i-1].Prefix > size.Prefix)) {
+		i--
+	}
+	if i == largestPostings {
+		return list
+	}
+	if len(list) < largestPostings {
+		list = append(list, PostingSize{})
+	}
+	copy(list[i+1:], list[i:])
+	list[i] = size
+	return list
+}
+
+// heapSize returns the bytes of the node without its children
+func (t *Node) heapSize() int64 {
+	size := nodeSize + int64(len(t.currentWord))
+	size += mapSize(len(t.children), 4+pointerSize)
+	size += wordsSize(t.correctWords) + wordsSize(t.possibleWords)
+	size += int64(cap(t.topWords)) * wordCountSize
+	return size + t.correctData.heapSize() + t.possibleData.heapSize()
+}
+
+func (p *postings) heapSize() int64 {
+	size := int64(cap(p.docs.keys))*2 + int64(cap(p.docs.containers))*containerSize
+	for _, c := range p.docs.containers {
+		size += int64(cap(c.array))*2 + int64(cap(c.bitset))*8
+	}
+	size += int64(cap(p.positions)) * sliceSize
+	for _, positions := range p.positions {
+		size += int64(cap(positions)) * intSize
+	}
+	return size
+}
+
+// positionCount returns the number of positions of every document of the postings
+func (p *postings) positionCount() int {
+	count := 0
+	for _, positions := range p.positions {
+		count += len(positions)
+	}
+	return count
+}
+
+func (d *documentTable) heapSize() int64 {
+	size := mapSize(len(d.ordinals), stringSize+4)
+	size += int64(cap(d.ids)+cap(d.names)+cap(d.words)) * stringSize
+	for i := range d.ids {
+		size += int64(len(d.ids[i]) + len(d.names[i]) + len(d.words[i]))
+	}
+	return size
+}
+
+func wordsSize(words map[string]int) int64 {
+	size := mapSize(len(words), stringSize+intSize)
+	for word := range words {
+		size += int64(len(word))
+	}
+	return size
+}
+
+// mapSize estimates a map with the entries, the buckets are kept between half and 13/16 full
+func mapSize(entries int, entrySize int64) int64 {
+	if entries == 0 {
+		return 0
+	}
+	return mapHeaderSize + int64(entries)*(entrySize+1)*3/2
+}
@@ -0,0 +1,91 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func Test_Stats(t *testing.T) {
+	cases := map[string]struct {
+		trie     *Node
+		expected Stats
+	}{
+		"Empty trie": {NewNode(), Stats{}},
+		"Three documents": {introspectTrie(), Stats{
+			Nodes:       7,
+			SuffixNodes: 25,
+			Words:       5,
+			Documents:   3,
+			Postings:    12,
+			Positions:   13,
+			Depths:      []int{3, 2, 2},
+			LargestCorrect: []PostingSize{
+				{"direito", 2}, {"civil", 1}, {"defensiva", 1}, {"direcao", 1}, {"penal", 1},
+			},
+			LargestPossible: []PostingSize{
+				{"d", 3}, {"di", 3}, {"dire", 3}, {"direi", 2}, {"c", 1}, {"de", 1}, {"direc", 1}, {"p", 1},
+			},
+		}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			stats := tc.trie.Stats()
+			diff := cmp.Diff(tc.expected, stats, cmpopts.IgnoreFields(Stats{}, "HeapSize"), cmpopts.EquateEmpty())
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+			if stats.HeapSize <= 0 {
+				t.Fatalf("\nExpected: a positive heap size\nGot: %v", stats.HeapSize)
+			}
+		})
+	}
+}
+
+func Test_StatsLargest(t *testing.T) {
+	trie := syntheticTrie(4000)
+	stats := trie.Stats()
+	if len(stats.LargestCorrect) != largestPostings || len(stats.LargestPossible) != largestPostings {
+		t.Fatalf("\nExpected: %v largest postings\nGot: %v and %v", largestPostings, len(stats.LargestCorrect), len(stats.LargestPossible))
+	}
+	// the largest correct postings must be the same found by the maximum size of the IDs
+	if stats.LargestCorrect[0].IDs != trie.GetMaximumSizeOfCorrectIds() {
+		t.Fatalf("\nExpected: %v\nGot: %v", trie.GetMaximumSizeOfCorrectIds(), stats.LargestCorrect[0].IDs)
+	}
+	if stats.LargestPossible[0].IDs != trie.GetMaximumSizeOfPossibleIds() {
+		t.Fatalf("\nExpected: %v\nGot: %v", trie.GetMaximumSizeOfPossibleIds(), stats.LargestPossible[0].IDs)
+	}
+	for i := 1; i < largestPostings; i++ {
+		if stats.LargestPossible[i].IDs > stats.LargestPossible[i-1].IDs {
+			t.Fatalf("\nExpected: the postings ordered by size\nGot: %v", stats.LargestPossible)
+		}
+		if ids := len(trie.GetCorrectIDs(stats.LargestCorrect[i].Prefix)); ids != stats.LargestCorrect[i].IDs {
+			t.Fatalf("\nExpected: %v\nGot: %v", stats.LargestCorrect[i].IDs, ids)
+		}
+	}
+
+	words := 0
+	trie.WalkPrefix("", func(word string, ids []string) bool {
+		words++
+		return true
+	})
+	if stats.Words != words {
+		t.Fatalf("\nExpected: %v\nGot: %v", words, stats.Words)
+	}
+}
+
+func Test_StatsHeapSize(t *testing.T) {
+	trie := introspectTrie()
+	before := trie.Stats().HeapSize
+	trie.Add("4", "Palavra Completamente Nova")
+	added := trie.Stats().HeapSize
+	if added <= before {
+		t.Fatalf("\nExpected: more than %v\nGot: %v", before, added)
+	}
+	trie.Remove("4")
+	if removed := trie.Stats().HeapSize; removed >= added {
+		t.Fatalf("\nExpected: less than %v\nGot: %v", added, removed)
+	}
+}
@@ -1,49 +1,313 @@
 package trie
 
-import "regexp"
+import (
+	"os"
+	"regexp"
+	"sync"
+	"time"
+)
 
 // Min word size to get in the Trie
 const minWordSize = 3
 
+// Number of best completions kept in every node, bigger requests are computed with a bounded heap
+const maxCompletions = 10
+
+// Number of documents analyzed together by a worker of the BulkLoader
+const bulkBatchSize = 256
+
+// Number of largest posting lists returned by Stats
+const largestPostings = 10
+
 // Regex is declared as global to the package so it is not compiled on every execution
 var rxp = regexp.MustCompile("[^A-Za-zÀ-ÖØ-öø-ÿ0-9-_]+")
 
 // Node is the data structure that hold IDs and runes of an object
+// The trie is compressed, a node holds the complete prefix in currentWord and its children are indexed by the first rune of their edge
 type Node struct {
-	possibleData  map[string]*internalOrderData
-	correctData   map[string]*internalOrderData
+	possibleData  postings
+	correctData   postings
 	possibleWords map[string]int
 	correctWords  map[string]int
 	currentWord   string
 	isWord        bool
 	children      map[rune]*Node
+	topWords      []wordCount
+	// suffixes, documents and observer are only set in the root node
+	// suffixes indexes every suffix of the inserted words and documents holds the IDs and names of the postings
+	suffixes  *Node
+	documents *documentTable
+	observer  Observer
+}
+
+// Index is a read only trie loaded from a file written by WriteIndex
+// the words, documents and postings are read directly from the file mapped in memory when they are searched
+type Index struct {
+	documents indexDocuments
+	words     indexSection
+	suffixes  indexSection
+	unmap     func() error
+	observer  Observer
+}
+
+// ShardedIndex partitions the documents in many tries by the hash of their IDs, so documents of different shards are added at the same time
+// the searches run in every shard concurrently and the results are merged in the same order of a single trie
+// every shard has its own lock, so the ShardedIndex is safe for concurrent use
+type ShardedIndex struct {
+	shards []*Node
+	locks  []sync.Mutex
+}
+
+// DurableNode is a trie whose changes are written in an append-only log before they are applied
+// the trie is recovered from the last snapshot and the changes of the log written after it, Compact writes a new snapshot
+// It is not safe for concurrent use, as the trie it changes
+type DurableNode struct {
+	trie     *Node
+	file     *os.File
+	size     int64
+	snapshot string
+	log      string
+	// err is set when a compaction failed and the log could not be reset or a rename synced, every change returns it
+	err error
 }
 
 // Pagination data for selecting the number of ids in the trie
+// In the cursor mode the page is ignored, the results after the item of the Cursor are returned
+// and NextCursor has the cursor of the last item when there are more results
 type Pagination struct {
-	PerPage int32
-	Page    int32
-	Total   int32
+	PerPage    int32
+	Page       int32
+	Total      int32
+	Mode       PaginationMode
+	Cursor     string
+	NextCursor string
 }
 
+// PaginationMode selects how the first item of a page is found
+type PaginationMode int
+
+const (
+	// PageMode skips the items of the pages before the page, so the items shift if data is added between the requests
+	PageMode PaginationMode = iota
+	// CursorMode starts after the item of the cursor, the first page is the one requested without a cursor
+	CursorMode
+)
+
 // SearchData returns the ID and name found in the trie
 type SearchData struct {
 	ID   string
 	Name string
 }
 
+// Document is the ID and name of a document added by AddBatch or a BulkLoader
+type Document struct {
+	ID   string
+	Name string
+}
+
+// SearchOptions bounds the work of SearchContext and selects the hits returned
+type SearchOptions struct {
+	// MaxCandidates is the maximum number of documents of the term of the phrase with the fewest documents, zero has no maximum
+	// they are the only documents that may match the phrase, so a search with more candidates is stopped before they are intersected
+	MaxCandidates int
+	// Limit is the maximum number of hits returned, zero returns every hit
+	Limit int
+	// Pagination returns only the hits of the page instead of the Limit, the pagination of the page is in the result
+	Pagination *Pagination
+	// Facets is the number of words counted for every term of the phrase that is a prefix, zero does not count them
+	Facets int
+}
+
+// SearchResult returns the hits of a search and the total of documents found
+type SearchResult struct {
+	Hits  []Hit
+	Total int
+	Took  time.Duration
+	// Facets has the words that complete every term that is a prefix, with the number of documents found that have the word
+	// the words are ordered by the number of documents
+	Facets map[string][]FacetCount
+	// Pagination is the pagination of the page when the options have one, as returned by SearchByRelevancePaginated
+	Pagination Pagination
+}
+
+// Hit is a document found by a search
+// Score is 1 when every term is a complete word and the first word of the name is matched,
+// a term that is a prefix counts half and the score is divided by the position of the first matched word plus one
+type Hit struct {
+	ID        string
+	Name      string
+	Score     float64
+	Terms     []string
+	Positions []int
+	MatchType MatchType
+}
+
+// MatchType tells if the terms of a search matched complete words or only the beginning of longer words
+type MatchType int
+
+const (
+	// MatchExact is a hit where every term of the phrase is a complete word
+	MatchExact MatchType = iota
+	// MatchPrefix is a hit where at least one term is only the beginning of the words matched
+	MatchPrefix
+)
+
+func (m MatchType) String() string {
+	if m == MatchPrefix {
+		return "prefix"
+	}
+	return "exact"
+}
+
+// FacetCount is a word that completes a term and the number of documents found with it
+type FacetCount struct {
+	Word  string
+	Count int
+}
+
+// Match is the position of a matched word in a name, Start and End are byte offsets
+// and RuneStart and RuneEnd are the same offsets counted in runes
+type Match struct {
+	Start     int
+	End       int
+	RuneStart int
+	RuneEnd   int
+	Exact     bool
+}
+
+// HighlightedData returns the data found in the trie with the matches in its name
+type HighlightedData struct {
+	SearchData
+	Matches []Match
+}
+
+// Explanation tells how a document is ranked by a search, Found is false when the document does not have every term
+// the documents are ordered by the Positions of the terms, the first different position or the shorter list is ranked first,
+// then by the NameSize, the Name and the ID, the smaller is ranked first
+type Explanation struct {
+	ID        string
+	Name      string
+	Found     bool
+	Rank      int
+	Total     int
+	Terms     []TermExplanation
+	Positions []int
+	NameSize  int
+	Score     float64
+	MatchType MatchType
+}
+
+// TermExplanation is a word of the phrase and the node it was resolved to, the longest prefix of the word in the trie
+// the documents of the node are the ones of the complete word when it is exact and the ones of the longer words when it is a prefix
+// Matched tells if the document is in the node and Positions are the positions of the node in the document
+type TermExplanation struct {
+	Word      string
+	Node      string
+	MatchType MatchType
+	Documents int
+	Matched   bool
+	Positions []int
+}
+
+// NodeInfo has the data kept in the node of a prefix of the trie
+// the correct counts are of the words that end in the prefix and the possible counts of the longer words that pass by it
+type NodeInfo struct {
+	Prefix        string
+	IsWord        bool
+	CorrectIDs    int
+	PossibleIDs   int
+	CorrectWords  int
+	PossibleWords int
+}
+
+// Stats has the size of the trie and an estimate of the memory it uses
+// Depths has the number of nodes at every depth, the children of the root are at the index 0
+// HeapSize is an estimate in bytes of the nodes, the postings, the suffixes and the documents, the runtime overhead is approximated
+type Stats struct {
+	Nodes           int
+	SuffixNodes     int
+	Words           int
+	Documents       int
+	Postings        int
+	Positions       int
+	Depths          []int
+	LargestCorrect  []PostingSize
+	LargestPossible []PostingSize
+	HeapSize        int64
+}
+
+// PostingSize is the number of IDs of the correct or possible postings of a prefix
+type PostingSize struct {
+	Prefix string
+	IDs    int
+}
+
+// PhraseCompletion returns the documents and the suggested phrases for a phrase that is being typed
+type PhraseCompletion struct {
+	Documents []SearchData
+	Phrases   []string
+}
+
+// documentTable maps the external IDs to the dense ordinals used in the postings, so IDs and names are kept only once
+// words has the original words inserted for every document separated by spaces, so Remove takes out the same words that were added
+type documentTable struct {
+	ordinals map[string]uint32
+	ids      []string
+	names    []string
+	words    []string
+}
+
+// documentSource returns the ID and the name of a document ordinal, it is the table of the trie or the one of an index file
+type documentSource interface {
+	document(doc uint32) (id, name string)
+}
+
+// postings are the documents of the words that reached a node in a compressed bitmap
+// the positions of the words of every document are kept in the same order of the bitmap
+type postings struct {
+	docs      bitmap
+	positions [][]int
+}
+
+// analyzedWord is a word of a name ready to be inserted, with the original word, the cleaned word and its position in the name
+type analyzedWord struct {
+	word     string
+	cleaned  string
+	position int
+}
+
 type internalOrderData struct {
 	id       string
 	name     string
 	position []int
 }
 
-type byRelevance []*internalOrderData
+// before returns true if the data is more relevant than the other data, the IDs are compared when everything else is the same
+func (d *internalOrderData) before(other *internalOrderData) bool {
+	if distance := dist(d.position, other.position); distance != 0 {
+		return distance == -1
+	}
+	if len(d.name) != len(other.name) {
+		return len(d.name) < len(other.name)
+	}
+	if d.name != other.name {
+		return d.name < other.name
+	}
+	return d.id < other.id
+}
+
+type wordCount struct {
+	word  string
+	count int
+}
 
-func (n byRelevance) Len() int { return len(n) }
-func (n byRelevance) Less(i, j int) bool {
-	return dist(n[i].position, n[j].position) == -1 ||
-		(dist(n[i].position, n[j].position) == 0 && len(n[i].name) < len(n[j].name)) ||
-		(dist(n[i].position, n[j].position) == 0 && len(n[i].name) == len(n[j].name) && n[i].name < n[j].name)
+// before returns true if the word should be completed before the other word
+func (w wordCount) before(other wordCount) bool {
+	return w.count > other.count || (w.count == other.count && w.word < other.word)
 }
-func (n byRelevance) Swap(i, j int) { n[i], n[j] = n[j], n[i] }
+
+type byRelevance []*internalOrderData
+
+func (n byRelevance) Len() int           { return len(n) }
+func (n byRelevance) Less(i, j int) bool { return n[i].before(n[j]) }
+func (n byRelevance) Swap(i, j int)      { n[i], n[j] = n[j], n[i] }
@@ -0,0 +1,4 @@
+// Package triepb has the protobuf messages and the gRPC service of the trie, the code is generated from trie.proto
+package triepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative trie.proto
@@ -0,0 +1,386 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// The log file starts with the magic and the checksum of the snapshot it follows
+// every record has the size and the checksum of its data and then the data
+const (
+	logMagic        = "TRIELOG1"
+	logHeaderSize   = len(logMagic) + 4
+	logRecordHeader = 8
+)
+
+// Operations of the records of the log
+const (
+	logAdd byte = iota + 1
+	logRemove
+	logUpdate
+	logCompact
+)
+
+// ErrInvalidLog is returned when the file is not a log written by a DurableNode or a record of it is damaged
+var ErrInvalidLog = errors.New("trie: invalid log file")
+
+// ErrLogMismatch is returned when the log follows another snapshot and it is not left over from an interrupted compaction
+var ErrLogMismatch = errors.New("trie: log does not follow the snapshot")
+
+// errRecordChecksum is returned by readRecord when the data of a complete record does not have its checksum
+var errRecordChecksum = errors.New("trie: wrong checksum of a log record")
+
+// logTable is the table of the checksums of the records and of the snapshots
+var logTable = crc32.MakeTable(crc32.Castagnoli)
+
+// logRecord is a change of the trie, the remove list of an addition is kept so it is replayed in the same way
+// a compaction is also written, with the checksum of its snapshot, so its log is known when the process stops before it is replaced
+type logRecord struct {
+	op       byte
+	id       string
+	name     string
+	remove   []string
+	checksum uint32
+}
+
+// OpenDurable loads the snapshot, if it exists, and replays the changes of the log written after it
+// the log is created when it does not exist, and a record that was only partially written when the process stopped is discarded
+// a log that follows another snapshot returns ErrLogMismatch, unless it was left by a compaction of this snapshot that did not finish
+func OpenDurable(snapshot, log string) (*DurableNode, error) {
+	data, err := ioutil.ReadFile(snapshot)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	t := NewNode()
+	if err == nil {
+		if t, err = ReadNode(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(log, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	d := &DurableNode{trie: t, file: file, snapshot: snapshot, log: log}
+	if err := d.recover(crc32.Checksum(data, logTable)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// Trie returns the trie to be searched, it must only be changed by the DurableNode, so every change is in the log
+func (d *DurableNode) Trie() *Node {
+	return d.trie
+}
+
+// Add writes the document in the log and then adds it to the trie in the same way as Node.Add
+func (d *DurableNode) Add(id, name string, remove ...string) error {
+	record := logRecord{op: logAdd, id: id, name: name, remove: remove}
+	if err := d.append(record); err != nil {
+		return err
+	}
+	record.apply(d.trie)
+	return nil
+}
+
+// Remove writes the removal in the log and then removes the document from the trie
+// an ID that is not in the trie is not written, it returns false in the same way as Node.Remove
+func (d *DurableNode) Remove(id string) (bool, error) {
+	if _, ok := d.trie.documents.ordinals[id]; !ok {
+		return false, nil
+	}
+	record := logRecord{op: logRemove, id: id}
+	if err := d.append(record); err != nil {
+		return false, err
+	}
+	return record.apply(d.trie), nil
+}
+
+// Update writes the change in the log and then replaces the document of the ID by the new name, in a single record
+// the remove list is the one of the new name, the old name is removed with the words it was added with
+// It returns true if the ID was already in the trie
+func (d *DurableNode) Update(id, name string, remove ...string) (bool, error) {
+	record := logRecord{op: logUpdate, id: id, name: name, remove: remove}
+	if err := d.append(record); err != nil {
+		return false, err
+	}
+	return record.apply(d.trie), nil
+}
+
+// Compact writes the trie in a new snapshot and starts an empty log after it
+// the snapshot and the log are written in temporary files and then renamed, if the process stops between both renames,
+// the old log ends with the compaction of the new snapshot, so its changes, already in the snapshot, are not replayed again
+// the directory is synced after every rename, so Compact only returns nil when both renames are in the disk
+// when the log is not renamed after the snapshot, the open log is reset to follow the new snapshot,
+// and if it can not be reset or a rename is not synced the DurableNode fails every change after it
+func (d *DurableNode) Compact() error {
+	if d.err != nil {
+		return d.err
+	}
+	checksum := crc32.New(logTable)
+	snapshot, err := writeTemp(d.snapshot, func(w io.Writer) error {
+		return d.trie.WriteIndex(io.MultiWriter(w, checksum))
+	})
+	if err != nil {
+		return err
+	}
+	defer os.Remove(snapshot)
+	log, err := writeTemp(d.log, func(w io.Writer) error {
+		_, err := w.Write(logHeader(checksum.Sum32()))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	defer os.Remove(log)
+	if err := d.append(logRecord{op: logCompact, checksum: checksum.Sum32()}); err != nil {
+		return err
+	}
+	// the new log is opened before the renames, so it does not fail after the snapshot is replaced
+	file, err := os.OpenFile(log, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(snapshot, d.snapshot); err != nil {
+		file.Close()
+		return err
+	}
+	// the log still ends with the compaction, so it is recovered with any of the snapshots, but no change may follow it
+	if err := syncDir(d.snapshot); err != nil {
+		file.Close()
+		d.err = err
+		return err
+	}
+	if err := os.Rename(log, d.log); err != nil {
+		file.Close()
+		if resetErr := d.reset(checksum.Sum32()); resetErr != nil {
+			d.err = resetErr
+		}
+		return err
+	}
+	d.file.Close()
+	d.file, d.size = file, int64(logHeaderSize)
+	// a change written in the new log before it is in the directory would be lost with the old log
+	if err := syncDir(d.log); err != nil {
+		d.err = err
+		return err
+	}
+	return nil
+}
+
+// Close closes the log, the changes are already in it, so nothing is lost if Compact is not called before
+func (d *DurableNode) Close() error {
+	return d.file.Close()
+}
+
+// recover replays the records of the log when it follows the snapshot with the checksum and discards a partial record in the end
+// a record with a wrong checksum is also discarded when no complete record follows it, as it was being written when the process stopped
+// a log of another snapshot is reset only when it ends with the compaction of the snapshot, otherwise it returns ErrLogMismatch
+func (d *DurableNode) recover(checksum uint32) error {
+	data, err := ioutil.ReadAll(d.file)
+	if err != nil {
+		return err
+	}
+	if len(data) < logHeaderSize {
+		return d.reset(checksum)
+	}
+	if string(data[:len(logMagic)]) != logMagic {
+		return ErrInvalidLog
+	}
+	var records []logRecord
+	offset := logHeaderSize
+	for offset < len(data) {
+		record, size, err := readRecord(data[offset:])
+		if err == errRecordChecksum && !recordAfter(data[offset+1:]) {
+			break
+		}
+		if err != nil {
+			return ErrInvalidLog
+		}
+		if size == 0 {
+			break
+		}
+		records = append(records, record)
+		offset += size
+	}
+	if binary.LittleEndian.Uint32(data[len(logMagic):]) != checksum {
+		if len(records) == 0 || records[len(records)-1].op != logCompact || records[len(records)-1].checksum != checksum {
+			return ErrLogMismatch
+		}
+		return d.reset(checksum)
+	}
+	for _, record := range records {
+		record.apply(d.trie)
+	}
+	d.size = int64(offset)
+	if offset < len(data) {
+		return d.file.Truncate(d.size)
+	}
+	return nil
+}
+
+// reset truncates the log and writes the header of the snapshot with the checksum
+func (d *DurableNode) reset(checksum uint32) error {
+	if err := d.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := d.file.WriteAt(logHeader(checksum), 0); err != nil {
+		return err
+	}
+	d.size = int64(logHeaderSize)
+	return d.file.Sync()
+}
+
+// append writes the record in the end of the log and waits until it is in the disk, it fails after a failed compaction
+// when the record is not completely written it is truncated, so the next record starts in the right place
+func (d *DurableNode) append(record logRecord) error {
+	if d.err != nil {
+		return d.err
+	}
+	data := record.encode()
+	_, err := d.file.WriteAt(data, d.size)
+	if err == nil {
+		err = d.file.Sync()
+	}
+	if err != nil {
+		d.file.Truncate(d.size)
+		return err
+	}
+	d.size += int64(len(data))
+	return nil
+}
+
+// apply changes the trie and returns the result of the removal, an addition always returns true and a compaction false
+func (r logRecord) apply(t *Node) bool {
+	switch r.op {
+	case logCompact:
+		return false
+	case logAdd:
+		t.Add(r.id, r.name, r.remove...)
+		return true
+	case logRemove:
+		return t.Remove(r.id)
+	}
+	removed := t.Remove(r.id)
+	t.Add(r.id, r.name, r.remove...)
+	return removed
+}
+
+// encode returns the size, the checksum and the data of the record, the data has the operation, the ID, the name and the remove list
+// or, in a compaction, the operation and the checksum of the snapshot
+func (r logRecord) encode() []byte {
+	data := []byte{r.op}
+	if r.op == logCompact {
+		data = append(data, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(data[1:], r.checksum)
+		return encodeRecord(data)
+	}
+	for _, value := range []string{r.id, r.name} {
+		data = append(appendUvarint(data, len(value)), value...)
+	}
+	data = appendUvarint(data, len(r.remove))
+	for _, pattern := range r.remove {
+		data = append(appendUvarint(data, len(pattern)), pattern...)
+	}
+	return encodeRecord(data)
+}
+
+// encodeRecord returns the size and the checksum of the data followed by it
+func encodeRecord(data []byte) []byte {
+	record := make([]byte, logRecordHeader, logRecordHeader+len(data))
+	binary.LittleEndian.PutUint32(record, uint32(len(data)))
+	binary.LittleEndian.PutUint32(record[4:], crc32.Checksum(data, logTable))
+	return append(record, data...)
+}
+
+// readRecord returns the record in the beginning of the data and its size, the size is zero when the record is not complete
+// a complete record with a wrong checksum returns errRecordChecksum and one with an unknown operation returns ErrInvalidLog
+func readRecord(data []byte) (logRecord, int, error) {
+	if len(data) < logRecordHeader {
+		return logRecord{}, 0, nil
+	}
+	size := binary.LittleEndian.Uint32(data)
+	// a record always has the operation, a size of zero is the part of the file allocated but not written
+	if size == 0 || uint64(size) > uint64(len(data)-logRecordHeader) {
+		return logRecord{}, 0, nil
+	}
+	raw := data[logRecordHeader : logRecordHeader+int(size)]
+	if crc32.Checksum(raw, logTable) != binary.LittleEndian.Uint32(data[4:]) {
+		return logRecord{}, 0, errRecordChecksum
+	}
+	if raw[0] == logCompact {
+		if len(raw) != 5 {
+			return logRecord{}, 0, ErrInvalidLog
+		}
+		return logRecord{op: logCompact, checksum: binary.LittleEndian.Uint32(raw[1:])}, logRecordHeader + int(size), nil
+	}
+	d := &decoder{data: raw[1:]}
+	record := logRecord{op: raw[0]}
+	record.id = string(d.bytes(d.uvarint()))
+	record.name = string(d.bytes(d.uvarint()))
+	for n := d.uvarint(); n > 0 && !d.failed; n-- {
+		record.remove = append(record.remove, string(d.bytes(d.uvarint())))
+	}
+	if d.failed || len(d.data) > 0 || record.op < logAdd || record.op > logUpdate {
+		return logRecord{}, 0, ErrInvalidLog
+	}
+	return record, logRecordHeader + int(size), nil
+}
+
+// recordAfter returns true if a complete record with the right checksum starts in any byte of the data
+// the size of a damaged record may also be damaged, so the records after it are looked for in every offset
+func recordAfter(data []byte) bool {
+	for offset := range data {
+		if _, size, err := readRecord(data[offset:]); err == nil && size > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func logHeader(checksum uint32) []byte {
+	header := make([]byte, logHeaderSize)
+	copy(header, logMagic)
+	binary.LittleEndian.PutUint32(header[len(logMagic):], checksum)
+	return header
+}
+
+// syncDir waits until the entries of the directory of the path are in the disk, so a file renamed in it is not lost
+func syncDir(path string) error {
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	err = dir.Sync()
+	if closeErr := dir.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeTemp writes a temporary file in the directory of the path and waits until it is in the disk, it returns the name of the file
+func writeTemp(path string, write func(w io.Writer) error) (string, error) {
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return "", err
+	}
+	err = write(file)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
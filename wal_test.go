@@ -0,0 +1,302 @@
+package trie
+
+import (
+	"errors"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// durableChanges makes the same changes in a DurableNode and in a trie
+func durableChanges(t *testing.T, d *DurableNode, expected *Node) {
+	for _, doc := range indexDocs {
+		if err := d.Add(doc.ID, doc.Name); err != nil {
+			t.Fatal(err)
+		}
+		expected.Add(doc.ID, doc.Name)
+	}
+	if err := d.Add("8", "Direito-Tributário", "-"); err != nil {
+		t.Fatal(err)
+	}
+	expected.Add("8", "Direito-Tributário", "-")
+	if removed, err := d.Remove("1"); err != nil || !removed {
+		t.Fatalf("\nExpected: %v\nGot: %v %v", true, removed, err)
+	}
+	expected.Remove("1")
+	if removed, err := d.Remove("99"); err != nil || removed {
+		t.Fatalf("\nExpected: %v\nGot: %v %v", false, removed, err)
+	}
+	if replaced, err := d.Update("2", "Processo Civil"); err != nil || !replaced {
+		t.Fatalf("\nExpected: %v\nGot: %v %v", true, replaced, err)
+	}
+	expected.Remove("2")
+	expected.Add("2", "Processo Civil")
+}
+
+func openDurable(t *testing.T, dir string) *DurableNode {
+	d, err := OpenDurable(filepath.Join(dir, "trie.idx"), filepath.Join(dir, "trie.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func Test_Durable(t *testing.T) {
+	cases := map[string]struct {
+		// change runs after the changes, before the DurableNode is closed, and damage after it is closed
+		change func(t *testing.T, d *DurableNode, expected *Node)
+		damage func(t *testing.T, dir string)
+	}{
+		"Changes replayed": {},
+		"Compacted": {
+			change: func(t *testing.T, d *DurableNode, expected *Node) {
+				if err := d.Compact(); err != nil {
+					t.Fatal(err)
+				}
+				if err := d.Add("9", "Direito Penal Especial"); err != nil {
+					t.Fatal(err)
+				}
+				expected.Add("9", "Direito Penal Especial")
+			},
+		},
+		"Partial record in the end": {
+			damage: func(t *testing.T, dir string) {
+				record := logRecord{op: logAdd, id: "9", name: "Direito Penal Especial"}.encode()
+				appendFile(t, filepath.Join(dir, "trie.log"), record[:len(record)-3])
+			},
+		},
+		"Damaged record in the end": {
+			damage: func(t *testing.T, dir string) {
+				record := logRecord{op: logAdd, id: "9", name: "Direito Penal Especial"}.encode()
+				copy(record[len(record)-5:], make([]byte, 5))
+				appendFile(t, filepath.Join(dir, "trie.log"), record)
+			},
+		},
+		"Log not renamed after the snapshot": {
+			change: func(t *testing.T, d *DurableNode, expected *Node) {
+				log := d.log
+				d.log = lockedPath(t, filepath.Dir(log))
+				if err := d.Compact(); err == nil {
+					t.Fatalf("\nExpected: an error\nGot: %v", err)
+				}
+				d.log = log
+				if err := d.Add("9", "Direito Penal Especial"); err != nil {
+					t.Fatal(err)
+				}
+				expected.Add("9", "Direito Penal Especial")
+			},
+		},
+		"Log of the snapshot before a compaction": {
+			change: func(t *testing.T, d *DurableNode, expected *Node) {
+				old, err := ioutil.ReadFile(d.log)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := d.Compact(); err != nil {
+					t.Fatal(err)
+				}
+				// the old log ends with the compaction, as when the process stopped before it was replaced
+				snapshot, err := ioutil.ReadFile(d.snapshot)
+				if err != nil {
+					t.Fatal(err)
+				}
+				old = append(old, logRecord{op: logCompact, checksum: crc32.Checksum(snapshot, logTable)}.encode()...)
+				if err := ioutil.WriteFile(d.log, old, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			d := openDurable(t, dir)
+			expected := NewNode()
+			durableChanges(t, d, expected)
+			if tc.change != nil {
+				tc.change(t, d, expected)
+			}
+			if err := d.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if tc.damage != nil {
+				tc.damage(t, dir)
+			}
+			recovered := openDurable(t, dir)
+			defer recovered.Close()
+			for method, search := range searcherCases {
+				diff := cmp.Diff(search(expected), search(recovered.Trie()))
+				if diff != "" {
+					t.Fatalf("%s: %s", method, diff)
+				}
+			}
+			// the log keeps working after the recovery
+			if err := recovered.Add("10", "Direito Militar"); err != nil {
+				t.Fatal(err)
+			}
+			recovered.Close()
+			expected.Add("10", "Direito Militar")
+			again := openDurable(t, dir)
+			defer again.Close()
+			diff := cmp.Diff(expected.SearchByRelevance("direito"), again.Trie().SearchByRelevance("direito"))
+			if diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func Test_DurableInvalidLog(t *testing.T) {
+	cases := map[string]struct {
+		damage func(data []byte) []byte
+	}{
+		"Not a log":                      {func(data []byte) []byte { return []byte("not a log file") }},
+		"Wrong checksum before a record": {func(data []byte) []byte { data[logHeaderSize+logRecordHeader]++; return data }},
+		"Unknown operation": {func(data []byte) []byte {
+			return append(data[:logHeaderSize], logRecord{op: 9, id: "1", name: "Direito"}.encode()...)
+		}},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			d := openDurable(t, dir)
+			durableChanges(t, d, NewNode())
+			d.Close()
+			path := filepath.Join(dir, "trie.log")
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, tc.damage(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := OpenDurable(filepath.Join(dir, "trie.idx"), path); err != ErrInvalidLog {
+				t.Fatalf("\nExpected: %v\nGot: %v", ErrInvalidLog, err)
+			}
+		})
+	}
+}
+
+func Test_DurableLogMismatch(t *testing.T) {
+	cases := map[string]struct {
+		// change runs after the changes and returns the log written over the log of the DurableNode after it is closed
+		change func(t *testing.T, d *DurableNode) []byte
+	}{
+		"Log of the snapshot before a finished compaction": {
+			change: func(t *testing.T, d *DurableNode) []byte {
+				old, err := ioutil.ReadFile(d.log)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := d.Compact(); err != nil {
+					t.Fatal(err)
+				}
+				return old
+			},
+		},
+		"Log ending with the compaction of another snapshot": {
+			change: func(t *testing.T, d *DurableNode) []byte {
+				old, err := ioutil.ReadFile(d.log)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := d.Compact(); err != nil {
+					t.Fatal(err)
+				}
+				return append(old, logRecord{op: logCompact, checksum: 1}.encode()...)
+			},
+		},
+		"Snapshot removed": {
+			change: func(t *testing.T, d *DurableNode) []byte {
+				if err := d.Compact(); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Remove(d.snapshot); err != nil {
+					t.Fatal(err)
+				}
+				return nil
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			d := openDurable(t, dir)
+			durableChanges(t, d, NewNode())
+			log := tc.change(t, d)
+			d.Close()
+			path := filepath.Join(dir, "trie.log")
+			if log != nil {
+				if err := ioutil.WriteFile(path, log, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := OpenDurable(filepath.Join(dir, "trie.idx"), path); err != ErrLogMismatch {
+				t.Fatalf("\nExpected: %v\nGot: %v", ErrLogMismatch, err)
+			}
+		})
+	}
+}
+
+func Test_DurableFailedCompaction(t *testing.T) {
+	dir := t.TempDir()
+	d := openDurable(t, dir)
+	defer d.Close()
+	expected := NewNode()
+	durableChanges(t, d, expected)
+	// the error of a log that could not be reset after the snapshot was replaced
+	d.err = errors.New("log not reset")
+	if err := d.Add("9", "Direito Penal Especial"); err != d.err {
+		t.Fatalf("\nExpected: %v\nGot: %v", d.err, err)
+	}
+	if _, err := d.Remove("3"); err != d.err {
+		t.Fatalf("\nExpected: %v\nGot: %v", d.err, err)
+	}
+	if _, err := d.Update("4", "Direito Militar"); err != d.err {
+		t.Fatalf("\nExpected: %v\nGot: %v", d.err, err)
+	}
+	if err := d.Compact(); err != d.err {
+		t.Fatalf("\nExpected: %v\nGot: %v", d.err, err)
+	}
+	diff := cmp.Diff(expected.SearchByRelevance("direito"), d.Trie().SearchByRelevance("direito"))
+	if diff != "" {
+		t.Fatalf(diff)
+	}
+}
+
+func Test_SyncDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := syncDir(filepath.Join(dir, "trie.idx")); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncDir(filepath.Join(dir, "missing", "trie.idx")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("\nExpected: %v\nGot: %v", os.ErrNotExist, err)
+	}
+}
+
+// lockedPath returns a path in the directory where no file can be renamed, as it is a directory that is not empty
+func lockedPath(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "locked")
+	if err := os.MkdirAll(filepath.Join(path, "file"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func appendFile(t *testing.T, path string, data []byte) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		t.Fatal(err)
+	}
+}